@@ -0,0 +1,230 @@
+package testctxlint
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// cancelIdiom is the pre-Go 1.24 idiom of deriving a cancelable context from
+// a root context and canceling it once the test is done, like
+//
+//	ctx, cancel := context.WithCancel(context.Background())
+//	defer cancel()
+//
+// As the test context is canceled once the test is done as well, the idiom
+// collapses into
+//
+//	ctx := t.Context()
+type cancelIdiom struct {
+	assign *ast.AssignStmt
+
+	// Statement canceling the context, or nil if the cancel function is
+	// discarded
+	cancel ast.Stmt
+
+	// Whether cancel is the only statement on its lines
+	ownLines bool
+}
+
+// findTestCancelIdioms records the cancel idioms within the blocks of the
+// function of the scope, which has a context source. Within nested functions
+// and goroutines, the context is canceled once they return, which is before
+// the test is done.
+func (c *scopeChecker) findTestCancelIdioms() {
+	ast.Inspect(c.s.Node, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return n == c.s.Node
+
+		case *ast.BlockStmt:
+			c.findCancelIdioms(n)
+		}
+
+		return true
+	})
+}
+
+// findCancelIdioms records the cancel idioms among the statements of block
+// by the calls creating their root contexts.
+func (c *scopeChecker) findCancelIdioms(block *ast.BlockStmt) {
+	info := c.r.pass.TypesInfo
+
+	for i, stmt := range block.List {
+		assign, ok := stmt.(*ast.AssignStmt)
+		if !ok || len(assign.Lhs) != 2 || len(assign.Rhs) != 1 {
+			continue
+		}
+
+		call, ok := ast.Unparen(assign.Rhs[0]).(*ast.CallExpr)
+		if !ok || len(call.Args) != 1 || !isWithCancel(typeutil.StaticCallee(info, call)) {
+			continue
+		}
+
+		root, ok := ast.Unparen(call.Args[0]).(*ast.CallExpr)
+		if !ok {
+			continue
+		}
+
+		if id, ok := assign.Lhs[0].(*ast.Ident); !ok || id.Name == "_" {
+			continue
+		}
+
+		idiom := &cancelIdiom{assign: assign}
+
+		if !idiom.findCancel(info, assign.Lhs[1], block.List[i+1:]) {
+			continue
+		}
+
+		// Deferred cancel functions of helpers and the like run once they
+		// return, long before the test context is canceled
+		if _, deferred := idiom.cancel.(*ast.DeferStmt); deferred && !c.s.test {
+			continue
+		}
+
+		if idiom.cancel != nil {
+			idiom.ownLines = c.onOwnLines(idiom.cancel, block)
+		}
+
+		c.cancelIdioms[root] = idiom
+	}
+}
+
+// isWithCancel reports whether fn is the WithCancel function of the standard
+// or the legacy context package.
+func isWithCancel(fn *types.Func) bool {
+	return fn != nil && (isFunctionNamed(fn, "context", "WithCancel") ||
+		isFunctionNamed(fn, xnetContextPath, "WithCancel"))
+}
+
+// findCancel finds the statement among stmts which cancels the context with
+// the cancel function expr, which must be the only use of it, either
+//
+//	defer cancel()
+//
+// or
+//
+//	t.Cleanup(cancel)
+//
+// It reports false if the cancel function is used otherwise, such as being
+// called early or passed to a goroutine.
+func (idiom *cancelIdiom) findCancel(info *types.Info, expr ast.Expr, stmts []ast.Stmt) bool {
+	id, ok := expr.(*ast.Ident)
+	if !ok {
+		return false
+	}
+
+	if id.Name == "_" {
+		return true
+	}
+
+	cancel, ok := info.Defs[id].(*types.Var)
+	if !ok {
+		return false
+	}
+
+	var uses []*ast.Ident
+
+	for use, obj := range info.Uses {
+		if obj == cancel {
+			uses = append(uses, use)
+		}
+	}
+
+	if len(uses) != 1 {
+		return false
+	}
+
+	for _, stmt := range stmts {
+		var arg ast.Expr
+
+		switch stmt := stmt.(type) {
+		case *ast.DeferStmt:
+			if len(stmt.Call.Args) == 0 {
+				arg = stmt.Call.Fun
+			}
+
+		case *ast.ExprStmt:
+			if call, ok := ast.Unparen(stmt.X).(*ast.CallExpr); ok && isCleanupCall(info, call) {
+				arg = call.Args[0]
+			}
+		}
+
+		if arg != nil && ast.Unparen(arg) == uses[0] {
+			idiom.cancel = stmt
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// onOwnLines reports whether stmt of block shares its lines with no other
+// statement or brace.
+func (c *scopeChecker) onOwnLines(stmt ast.Stmt, block *ast.BlockStmt) bool {
+	fset := c.r.pass.Fset
+	line := func(pos token.Pos) int { return fset.Position(pos).Line }
+
+	prev, next := block.Lbrace, block.Rbrace
+
+	for i, other := range block.List {
+		if other != stmt {
+			continue
+		}
+
+		if i > 0 {
+			prev = block.List[i-1].End()
+		}
+
+		if i < len(block.List)-1 {
+			next = block.List[i+1].Pos()
+		}
+	}
+
+	return line(prev) < line(stmt.Pos()) && line(stmt.End()) < line(next)
+}
+
+// reportCancelIdiom reports call, which creates the root context of idiom,
+// and suggests collapsing idiom into the assignment of replacement, which
+// refers to source, or the alternatives.
+func reportCancelIdiom(
+	r *reporter, call *ast.CallExpr, diagMessage string, source *ContextSource, replacement string,
+	idiom *cancelIdiom, alternatives ...analysis.SuggestedFix,
+) {
+	assign := idiom.assign
+	ctx := assign.Lhs[0].(*ast.Ident)
+
+	// ctx may have been declared before
+	tok := assign.Tok
+	if tok == token.DEFINE && r.pass.TypesInfo.Defs[ctx] == nil {
+		tok = token.ASSIGN
+	}
+
+	message := "replace " + types.ExprString(assign.Rhs[0]) + " with " + replacement
+	edits := []analysis.TextEdit{
+		{
+			Pos:     assign.Pos(),
+			End:     assign.End(),
+			NewText: []byte(ctx.Name + " " + tok.String() + " " + replacement),
+		},
+	}
+	removed := []ast.Node{assign}
+
+	if idiom.cancel != nil {
+		message += " and remove " + types.ExprString(assign.Lhs[1])
+
+		edit := analysis.TextEdit{Pos: idiom.cancel.Pos(), End: idiom.cancel.End()}
+		if idiom.ownLines {
+			edit = deleteLinesEdit(r.pass.Fset, idiom.cancel.Pos(), idiom.cancel.End())
+		}
+
+		edits = append(edits, edit)
+		removed = append(removed, idiom.cancel)
+	}
+
+	reportFix(r, call, diagMessage, message, source, edits, removed, alternatives...)
+}
@@ -0,0 +1,206 @@
+package testctxlint
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// cleanupReason explains why the test context is not used as is within
+// cleanup functions.
+const cleanupReason = "the test context is canceled before cleanup functions run"
+
+// cleanupParams returns the function parameters of the package's functions
+// which are registered as cleanup functions, such as fn in
+//
+//	func registerCleanup(t *testing.T, fn func()) {
+//		t.Cleanup(fn)
+//	}
+//
+// Parameters passed on to other such parameters are found as well.
+func cleanupParams(pass *analysis.Pass) map[*types.Var]bool {
+	params := map[*types.Var]bool{}
+
+	// Calls passing a parameter of the enclosing function
+	type forward struct {
+		call  *ast.CallExpr
+		param *types.Var
+		index int
+	}
+
+	var forwards []forward
+
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+
+			ast.Inspect(fn.Body, func(n ast.Node) bool {
+				call, ok := n.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+
+				for i, arg := range call.Args {
+					id, ok := ast.Unparen(arg).(*ast.Ident)
+					if !ok {
+						continue
+					}
+
+					param, ok := pass.TypesInfo.Uses[id].(*types.Var)
+					if !ok || !isParamOf(pass.TypesInfo, param, fn) {
+						continue
+					}
+
+					if isCleanupCall(pass.TypesInfo, call) {
+						params[param] = true
+					} else {
+						forwards = append(forwards, forward{call, param, i})
+					}
+				}
+
+				return true
+			})
+		}
+	}
+
+	// Follow parameters through helpers until nothing changes anymore
+	for changed := true; changed; {
+		changed = false
+
+		for _, f := range forwards {
+			if !params[f.param] && registersCleanupArg(pass.TypesInfo, params, f.call, f.index) {
+				params[f.param] = true
+				changed = true
+			}
+		}
+	}
+
+	return params
+}
+
+// isParamOf reports whether v is a parameter of the function declared by fn.
+func isParamOf(info *types.Info, v *types.Var, fn *ast.FuncDecl) bool {
+	for _, field := range fn.Type.Params.List {
+		for _, name := range field.Names {
+			if info.Defs[name] == v {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// isCleanupCall reports whether call registers a cleanup function through
+// the Cleanup method of a testing handle.
+func isCleanupCall(info *types.Info, call *ast.CallExpr) bool {
+	sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Cleanup" || len(call.Args) != 1 {
+		return false
+	}
+
+	if _, ok := typeIsTestingDotTOrB(info, sel.X); ok {
+		return true
+	}
+
+	// Cleanup may also be promoted from an embedded handle
+	fn, ok := info.Uses[sel.Sel].(*types.Func)
+
+	return ok && fn.Pkg() != nil && fn.Pkg().Path() == "testing"
+}
+
+// registersCleanupArg reports whether the argument at index of call ends up
+// registered as a cleanup function, either directly or through a helper
+// whose parameter is in params.
+func registersCleanupArg(info *types.Info, params map[*types.Var]bool, call *ast.CallExpr, index int) bool {
+	if isCleanupCall(info, call) {
+		return true
+	}
+
+	callee := typeutil.StaticCallee(info, call)
+	if callee == nil {
+		return false
+	}
+
+	sig := callee.Type().(*types.Signature)
+	if call.Ellipsis.IsValid() || index >= sig.Params().Len() ||
+		sig.Variadic() && index >= sig.Params().Len()-1 {
+		return false
+	}
+
+	return params[sig.Params().At(index)]
+}
+
+// isTestingContextCall reports whether call obtains the context of a testing
+// handle, like t.Context().
+func isTestingContextCall(info *types.Info, call *ast.CallExpr) bool {
+	sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Context" || len(call.Args) != 0 {
+		return false
+	}
+
+	if _, ok := typeIsTestingDotTOrB(info, sel.X); ok {
+		return true
+	}
+
+	fn, ok := info.Uses[sel.Sel].(*types.Func)
+
+	return ok && fn.Pkg() != nil && fn.Pkg().Path() == "testing"
+}
+
+// cleanupSource returns the source of a context which outlives the test
+// context of source, for use within cleanup functions. It returns nil if the
+// context package cannot be referred to in file.
+func cleanupSource(pass *analysis.Pass, file *ast.File, source *ContextSource) *ContextSource {
+	name, edits, ok := importedName(pass, file, "context")
+	if !ok {
+		return nil
+	}
+
+	cs := *source
+	cs.Expr = name + ".WithoutCancel(" + source.Expr + ")"
+	cs.Edits = append(edits, source.Edits...)
+	cs.Reason = cleanupReason
+
+	return &cs
+}
+
+// outlivingFixes returns the alternative fix replacing call, a call to the
+// forbidden function fn described by forbidden, with a context which keeps
+// the values of the test context of source but is not canceled along with
+// it, for work which must outlive the test. Unlike the first fix, it is
+// complete on its own, as alternative fixes are never merged.
+func (c *scopeChecker) outlivingFixes(
+	call *ast.CallExpr, fn *types.Func, forbidden string, source *ContextSource,
+) []analysis.SuggestedFix {
+	pass := c.r.pass
+
+	outliving := cleanupSource(pass, fileOf(pass, call.Pos()), source)
+	if outliving == nil {
+		return nil
+	}
+
+	replacement := outliving.Expr
+	if template := c.forbiddenFuncs[fn.FullName()]; template != "" {
+		replacement = expandTemplate(pass.Fset, template, outliving, call)
+	}
+
+	var edits []analysis.TextEdit
+	if tp := source.param; tp != nil && tp.isUnnamed {
+		edits = append(edits, tp.nameEdits()...)
+	}
+
+	edits = append(edits, analysis.TextEdit{Pos: call.Pos(), End: call.End(), NewText: []byte(replacement)})
+	edits = append(edits, outliving.Edits...)
+	edits = append(edits, outliving.shared...)
+
+	return []analysis.SuggestedFix{{
+		Message:   "replace " + forbidden + " with " + replacement + " to outlive the test, keeping its values",
+		TextEdits: edits,
+	}}
+}
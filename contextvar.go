@@ -0,0 +1,194 @@
+package testctxlint
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// contextVarCollector finds the variables within a test scope holding a
+// context which can be used instead of the test context.
+type contextVarCollector struct {
+	info   *types.Info
+	source *ContextSource
+
+	// Variables holding a usable context
+	vars map[*types.Var]bool
+
+	// Variables which are assigned other values after their declaration
+	reassigned map[*types.Var]bool
+
+	// Context variables by the cancel functions of their contexts
+	cancels map[*types.Var]*types.Var
+}
+
+// contextVarAt returns the variable declared within root which is visible at
+// pos and holds a context to use instead of the test context of source, or
+// nil. Candidates are named context.Context parameters, and local variables
+// holding the test context or a context derived from either through the
+// With functions of the context package, like ctx in
+//
+//	ctx, cancel := context.WithTimeout(t.Context(), time.Minute)
+//	defer cancel()
+//
+// The innermost or most recently declared candidate is returned. Variables
+// which are reassigned, or whose cancel function is called directly, are left
+// out, as their context may not be usable anymore at pos.
+func contextVarAt(
+	info *types.Info, pkg *types.Package, root ast.Node, source *ContextSource, pos token.Pos,
+) *types.Var {
+	c := &contextVarCollector{
+		info:       info,
+		source:     source,
+		vars:       map[*types.Var]bool{},
+		reassigned: map[*types.Var]bool{},
+		cancels:    map[*types.Var]*types.Var{},
+	}
+
+	ast.Inspect(root, c.visit)
+
+	inner := pkg.Scope().Innermost(pos)
+	if inner == nil {
+		return nil
+	}
+
+	var found *types.Var
+
+	for v := range c.vars {
+		if c.reassigned[v] || found != nil && v.Pos() < found.Pos() {
+			continue
+		}
+
+		// The variable must be declared before pos and not be shadowed
+		if _, obj := inner.LookupParent(v.Name(), pos); obj == v {
+			found = v
+		}
+	}
+
+	return found
+}
+
+func (c *contextVarCollector) visit(n ast.Node) bool {
+	switch n := n.(type) {
+	case *ast.FuncDecl:
+		c.addParams(n.Type)
+
+	case *ast.FuncLit:
+		c.addParams(n.Type)
+
+	case *ast.AssignStmt:
+		if n.Tok == token.DEFINE || n.Tok == token.ASSIGN {
+			c.addValues(n.Lhs, n.Rhs)
+		}
+
+	case *ast.ValueSpec:
+		names := make([]ast.Expr, len(n.Names))
+		for i, name := range n.Names {
+			names[i] = name
+		}
+
+		c.addValues(names, n.Values)
+
+	case *ast.ExprStmt:
+		// Contexts canceled early, as opposed to a deferred cancel
+		if call, ok := ast.Unparen(n.X).(*ast.CallExpr); ok {
+			if id, ok := ast.Unparen(call.Fun).(*ast.Ident); ok {
+				if v, ok := c.info.Uses[id].(*types.Var); ok && c.cancels[v] != nil {
+					c.reassigned[c.cancels[v]] = true
+				}
+			}
+		}
+	}
+
+	return true
+}
+
+// addParams adds the named context.Context parameters of fnType.
+func (c *contextVarCollector) addParams(fnType *ast.FuncType) {
+	for _, field := range fnType.Params.List {
+		for _, name := range field.Names {
+			if v, ok := c.info.Defs[name].(*types.Var); ok && name.Name != "_" && isContextType(v.Type()) {
+				c.vars[v] = true
+			}
+		}
+	}
+}
+
+// addValues adds the variables of an assignment or declaration which are
+// given a usable context, and marks reassigned variables.
+func (c *contextVarCollector) addValues(lhs, rhs []ast.Expr) {
+	// ctx, cancel := context.WithCancel(...)
+	if len(lhs) == 2 && len(rhs) == 1 {
+		if call, ok := ast.Unparen(rhs[0]).(*ast.CallExpr); ok && isContextDerivation(c.info, call) {
+			ctx, cancel := c.definedVar(lhs[0]), c.definedVar(lhs[1])
+			if ctx != nil && c.isUsableContext(call) {
+				c.vars[ctx] = true
+
+				if cancel != nil {
+					c.cancels[cancel] = ctx
+				}
+			}
+
+			return
+		}
+	}
+
+	for i, expr := range lhs {
+		v := c.definedVar(expr)
+		if v != nil && len(lhs) == len(rhs) && c.isUsableContext(rhs[i]) {
+			c.vars[v] = true
+		}
+	}
+}
+
+// definedVar returns the variable newly declared by expr on the left-hand
+// side of an assignment or declaration. Existing variables assigned by expr
+// are marked as reassigned.
+func (c *contextVarCollector) definedVar(expr ast.Expr) *types.Var {
+	id, ok := ast.Unparen(expr).(*ast.Ident)
+	if !ok {
+		return nil
+	}
+
+	if v, ok := c.info.Defs[id].(*types.Var); ok {
+		return v
+	}
+
+	if v, ok := c.info.Uses[id].(*types.Var); ok {
+		c.reassigned[v] = true
+	}
+
+	return nil
+}
+
+// isUsableContext reports whether expr evaluates to the test context, the
+// context of a usable variable, or a context derived from either.
+func (c *contextVarCollector) isUsableContext(expr ast.Expr) bool {
+	switch expr := ast.Unparen(expr).(type) {
+	case *ast.Ident:
+		v, ok := c.info.Uses[expr].(*types.Var)
+
+		return ok && c.vars[v]
+
+	case *ast.CallExpr:
+		if types.ExprString(expr) == c.source.Expr {
+			return true
+		}
+
+		return isContextDerivation(c.info, expr) && len(expr.Args) > 0 && c.isUsableContext(expr.Args[0])
+	}
+
+	return false
+}
+
+// isContextDerivation reports whether call derives a context from its first
+// argument through one of the With functions of the context package, such
+// as context.WithTimeout.
+func isContextDerivation(info *types.Info, call *ast.CallExpr) bool {
+	fn := typeutil.StaticCallee(info, call)
+
+	return fn != nil && fn.Pkg() != nil && fn.Pkg().Path() == "context" && strings.HasPrefix(fn.Name(), "With")
+}
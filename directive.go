@@ -0,0 +1,76 @@
+package testctxlint
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// ignoreDirective suppresses the diagnostics of the line it is written on,
+// followed by the reason for doing so, like
+//
+//	ctx := context.Background() //testctxlint:ignore the client outlives the test
+const ignoreDirective = "//testctxlint:ignore"
+
+// suppressed reports whether the line of pos has an ignore directive.
+func (r *reporter) suppressed(pos token.Pos) bool {
+	file := fileOf(r.pass, pos)
+	if file == nil {
+		return false
+	}
+
+	line := r.pass.Fset.Position(pos).Line
+
+	for _, group := range file.Comments {
+		for _, comment := range group.List {
+			if isIgnoreDirective(comment.Text) && r.pass.Fset.Position(comment.Pos()).Line == line {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// isIgnoreDirective reports whether the comment text is an ignore directive.
+func isIgnoreDirective(text string) bool {
+	rest, ok := strings.CutPrefix(text, ignoreDirective)
+
+	return ok && (rest == "" || rest[0] == ' ' || rest[0] == '\t')
+}
+
+// suppressFix returns the fix adding an ignore directive with a placeholder
+// for the reason to the line of diag in file. The directive goes before an
+// existing comment at the end of the line, if any.
+func suppressFix(fset *token.FileSet, file *ast.File, diag *analysis.Diagnostic) analysis.SuggestedFix {
+	tokFile := fset.File(diag.Pos)
+	line := tokFile.Line(diag.Pos)
+
+	edit := analysis.TextEdit{
+		Pos:     tokFile.Pos(tokFile.Size()),
+		NewText: []byte(" " + ignoreDirective + " <reason>"),
+	}
+
+	if line < tokFile.LineCount() {
+		// Before the line break
+		edit.Pos = tokFile.LineStart(line+1) - 1
+	}
+
+	for _, group := range file.Comments {
+		if c := group.List[0]; c.Pos() >= diag.End && tokFile.Line(c.Pos()) == line {
+			edit.Pos = c.Pos()
+			edit.NewText = []byte(ignoreDirective + " <reason> ")
+
+			break
+		}
+	}
+
+	edit.End = edit.Pos
+
+	return analysis.SuggestedFix{
+		Message:   "suppress with an " + ignoreDirective + " directive",
+		TextEdits: []analysis.TextEdit{edit},
+	}
+}
@@ -0,0 +1,192 @@
+package testctxlint
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// rootContextFact is exported for functions which create a root context
+// themselves instead of taking one, like
+//
+//	func newClient() *Client {
+//		return dial(context.Background())
+//	}
+//
+// Functions calling such functions get the fact as well.
+type rootContextFact struct {
+	// Function creating the root context, such as "context.Background"
+	Creates string
+}
+
+func (*rootContextFact) AFact() {}
+
+func (f *rootContextFact) String() string {
+	return "creates root context with " + f.Creates
+}
+
+// exportRootContextFacts exports a rootContextFact for each function of the
+// package which creates a root context, directly or through other functions
+// with the fact. Functions running within test scopes are skipped, as their
+// calls are reported themselves.
+func (a *analyzer) exportRootContextFacts(pass *analysis.Pass) {
+	var decls []*ast.FuncDecl
+
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			if decl, ok := decl.(*ast.FuncDecl); ok && decl.Body != nil && a.funcScope(pass, decl, nil) == nil {
+				decls = append(decls, decl)
+			}
+		}
+	}
+
+	// Repeat until no more facts are found, as functions may call functions
+	// declared later
+	for changed := true; changed; {
+		changed = false
+
+		for _, decl := range decls {
+			fn, ok := pass.TypesInfo.Defs[decl.Name].(*types.Func)
+			if !ok || pass.ImportObjectFact(fn, new(rootContextFact)) {
+				continue
+			}
+
+			if creates := a.createdRootContext(pass, decl); creates != "" {
+				pass.ExportObjectFact(fn, &rootContextFact{Creates: creates})
+				changed = true
+			}
+		}
+	}
+}
+
+// createdRootContext returns the name of the function decl calls to create
+// a root context, or an empty string. Function literals within it are not
+// taken into account, as it is unknown when they run. Neither are root
+// contexts only assigned to context parameters as a fallback, like in
+//
+//	if ctx == nil {
+//		ctx = context.Background()
+//	}
+func (a *analyzer) createdRootContext(pass *analysis.Pass, decl *ast.FuncDecl) string {
+	var creates string
+
+	fallbacks := map[ast.Expr]bool{}
+
+	ast.Inspect(decl.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+
+		case *ast.AssignStmt:
+			if len(n.Lhs) != len(n.Rhs) {
+				break
+			}
+
+			for i, lhs := range n.Lhs {
+				if isContextParam(pass.TypesInfo, decl.Type, lhs) {
+					fallbacks[ast.Unparen(n.Rhs[i])] = true
+				}
+			}
+
+		case *ast.CallExpr:
+			if fallbacks[n] {
+				return false
+			}
+
+			if x, sel, fn := forbiddenMethod(pass.TypesInfo, n, a.isForbidden); x != nil {
+				creates = formatMethod(sel, fn)
+			} else if fact := calleeRootContextFact(pass, n); fact != nil {
+				creates = fact.Creates
+			}
+		}
+
+		return creates == ""
+	})
+
+	return creates
+}
+
+// isContextParam reports whether expr refers to a context.Context parameter
+// of the function with type fnType.
+func isContextParam(info *types.Info, fnType *ast.FuncType, expr ast.Expr) bool {
+	id, ok := ast.Unparen(expr).(*ast.Ident)
+	if !ok {
+		return false
+	}
+
+	v, ok := info.Uses[id].(*types.Var)
+	if !ok || !isContextType(v.Type()) {
+		return false
+	}
+
+	for _, field := range fnType.Params.List {
+		for _, name := range field.Names {
+			if info.Defs[name] == v {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// calleeRootContextFact returns the rootContextFact of the function called by
+// call, or nil. Functions of other modules are left out, as they cannot be
+// changed to take a context.
+func calleeRootContextFact(pass *analysis.Pass, call *ast.CallExpr) *rootContextFact {
+	fn := typeutil.StaticCallee(pass.TypesInfo, call)
+	if fn == nil || fn.Pkg() == nil || !inModule(pass, fn.Pkg().Path()) {
+		return nil
+	}
+
+	fact := new(rootContextFact)
+	if !pass.ImportObjectFact(fn.Origin(), fact) {
+		return nil
+	}
+
+	return fact
+}
+
+// inModule reports whether the package with the given import path belongs to
+// the module of the package being analyzed.
+func inModule(pass *analysis.Pass, path string) bool {
+	if pass.Module == nil || pass.Module.Path == "" {
+		return false
+	}
+
+	return path == pass.Module.Path || strings.HasPrefix(path, pass.Module.Path+"/")
+}
+
+// checkRootContextHelperCall reports call if it calls a function which
+// creates its own root context.
+func (c *scopeChecker) checkRootContextHelperCall(call *ast.CallExpr) {
+	if c.s.findNearestContextSource() == nil {
+		return
+	}
+
+	fact := calleeRootContextFact(c.r.pass, call)
+	if fact == nil {
+		return
+	}
+
+	fn := typeutil.StaticCallee(c.r.pass.TypesInfo, call)
+
+	diag := analysis.Diagnostic{
+		Pos: call.Pos(),
+		End: call.End(),
+		Message: fmt.Sprintf("call to %s from a test routine, which creates its own root context with %s "+
+			"(the test context should be passed to %s instead)", fn.FullName(), fact.Creates, fn.Name()),
+	}
+
+	if c.refactorer != nil && fn.Pkg() == c.r.pass.Pkg {
+		if fix := c.refactorer.fix(fn); fix != nil {
+			diag.SuggestedFixes = []analysis.SuggestedFix{*fix}
+		}
+	}
+
+	c.r.report(diag)
+}
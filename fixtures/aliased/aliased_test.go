@@ -0,0 +1,44 @@
+package aliased_test
+
+import (
+	"context"
+	tst "testing"
+
+	"github.com/icedream/testctxlint/fixtures/internal/testing"
+)
+
+func example(ctx context.Context) {
+	go func() {
+		<-ctx.Done()
+	}()
+}
+
+func TestAliased(t *tst.T) {
+	example(context.Background()) // fix: example(t.Context())
+
+	t.Run("test", func(t2 *tst.T) {
+		example(context.TODO()) // fix: example(t2.Context())
+	})
+}
+
+func helperAliased(tb tst.TB) {
+	example(context.Background()) // fix: example(tb.Context())
+}
+
+func BenchmarkAliased(b *tst.B) {
+	helperAliased(b)
+
+	example(context.TODO()) // fix: example(b.Context())
+}
+
+// inHouse takes types from a package that is merely named testing, so it is
+// not a test scope.
+func inHouse(t *testing.T, tb testing.TB) {
+	example(context.Background())
+	example(t.Context())
+	example(tb.Context())
+}
+
+func TestInHouse(*tst.T) {
+	inHouse(&testing.T{}, &testing.T{}) // want-nofix
+}
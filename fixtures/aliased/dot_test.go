@@ -0,0 +1,24 @@
+package aliased_test
+
+import (
+	"context"
+	. "testing"
+)
+
+type testingT = T
+
+func TestDot(t *T) {
+	typeAliasHelper(t)
+
+	example(context.Background()) // fix: example(t.Context())
+}
+
+func helperDot(tb TB) {
+	example(context.TODO()) // fix: example(tb.Context())
+}
+
+func typeAliasHelper(t *testingT) {
+	helperDot(t)
+
+	example(context.Background()) // fix: example(t.Context())
+}
@@ -0,0 +1,139 @@
+package cancel_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+func example(context.Context) {}
+
+func TestDefer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background()) // fix: ctx := t.Context()
+	defer cancel()
+
+	example(ctx)
+}
+
+func TestCleanup(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO()) // fix: ctx := t.Context()
+	t.Cleanup(cancel)
+
+	example(ctx)
+}
+
+func TestRedeclared(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background()) // fix: ctx := t.Context()
+	defer cancel()
+
+	example(ctx)
+
+	ctx, cancel2 := context.WithCancel(context.Background()) // fix: ctx = t.Context()
+	defer cancel2()
+
+	example(ctx)
+}
+
+func TestSubtest(t *testing.T) {
+	t.Run("sub", func(t2 *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background()) // fix: ctx := t2.Context()
+		defer cancel()
+
+		example(ctx)
+	})
+}
+
+func TestClosure(t *testing.T) {
+	func() {
+		ctx, cancel := context.WithCancel(context.Background()) // fix: ctx, cancel := context.WithCancel(t.Context())
+		defer cancel()
+
+		example(ctx)
+	}()
+
+	go func() {
+		ctx, cancel := context.WithCancel(context.Background()) // fix: ctx, cancel := context.WithCancel(t.Context())
+		defer cancel()
+
+		example(ctx)
+	}()
+}
+
+func newServer(tb testing.TB) {
+	ctx, cancel := context.WithCancel(context.Background()) // fix: ctx, cancel := context.WithCancel(tb.Context())
+	defer cancel()
+
+	example(ctx)
+}
+
+func newClient(tb testing.TB) {
+	ctx, cancel := context.WithCancel(context.Background()) // fix: ctx := tb.Context()
+	tb.Cleanup(cancel)
+
+	example(ctx)
+}
+
+type CancelSuite struct {
+	suite.Suite
+}
+
+func (s *CancelSuite) SetupTest() {
+	ctx, cancel := context.WithCancel(context.Background()) // fix: ctx, cancel := context.WithCancel(s.T().Context())
+	defer cancel()
+
+	example(ctx)
+}
+
+func TestCancelSuite(t *testing.T) {
+	suite.Run(t, new(CancelSuite))
+}
+
+func TestHelpers(t *testing.T) {
+	newServer(t)
+	newClient(t)
+}
+
+func FuzzTarget(f *testing.F) {
+	f.Fuzz(func(t *testing.T, _ string) {
+		ctx, cancel := context.WithCancel(context.Background()) // fix: ctx := t.Context()
+		defer cancel()
+
+		example(ctx)
+	})
+}
+
+func TestCalledEarly(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background()) // fix: ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	example(ctx)
+	cancel()
+}
+
+func TestGoroutine(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background()) // fix: ctx, cancel := context.WithCancel(t.Context())
+
+	go func() {
+		defer cancel()
+
+		example(ctx)
+	}()
+}
+
+func TestPassed(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background()) // fix: ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	stop := cancel
+	_ = stop
+
+	example(ctx)
+}
+
+func TestTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 0) // fix: ctx, cancel := context.WithTimeout(t.Context(), 0)
+	defer cancel()
+
+	example(ctx)
+}
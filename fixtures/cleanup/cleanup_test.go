@@ -0,0 +1,102 @@
+package cleanup_test
+
+import (
+	"context"
+	"testing"
+)
+
+func drop(context.Context) {}
+
+func TestCleanup(t *testing.T) {
+	t.Cleanup(func() {
+		drop(context.Background()) // fix: drop(context.WithoutCancel(t.Context()))
+	})
+}
+
+func TestCleanupCanceled(t *testing.T) {
+	t.Cleanup(func() {
+		drop(t.Context()) // fix: drop(context.WithoutCancel(t.Context()))
+	})
+}
+
+func TestCleanupUncanceled(t *testing.T) {
+	t.Cleanup(func() {
+		drop(context.WithoutCancel(t.Context()))
+	})
+}
+
+func TestCleanupNested(t *testing.T) {
+	t.Cleanup(func() {
+		done := make(chan struct{})
+
+		go func() {
+			defer close(done)
+
+			drop(context.TODO()) // fix: drop(context.WithoutCancel(t.Context()))
+		}()
+
+		<-done
+	})
+}
+
+func TestCleanupSubtest(t *testing.T) {
+	t.Run("sub", func(t *testing.T) {
+		t.Cleanup(func() {
+			drop(context.Background()) // fix: drop(context.WithoutCancel(t.Context()))
+		})
+	})
+}
+
+func TestCleanupUnnamed(*testing.T) {
+	// The handle registering the cleanup cannot be unnamed, so this is not
+	// a cleanup function.
+	func() {
+		drop(context.Background()) // fix: drop(t.Context())
+	}()
+}
+
+func registerCleanup(t testing.TB, fn func()) {
+	t.Cleanup(fn)
+}
+
+func deferCleanup(t testing.TB, fn func()) {
+	registerCleanup(t, fn)
+}
+
+func TestCleanupHelper(t *testing.T) {
+	registerCleanup(t, func() {
+		drop(context.Background()) // fix: drop(context.WithoutCancel(t.Context()))
+	})
+
+	deferCleanup(t, func() {
+		drop(t.Context()) // fix: drop(context.WithoutCancel(t.Context()))
+	})
+}
+
+func runNow(fn func()) {
+	fn()
+}
+
+func TestNoCleanup(t *testing.T) {
+	runNow(func() {
+		drop(context.Background()) // fix: drop(t.Context())
+	})
+
+	drop(t.Context())
+}
+
+type fixture struct {
+	*testing.T
+}
+
+func (f fixture) setup() {
+	f.Cleanup(func() {
+		drop(context.Background()) // fix: drop(context.WithoutCancel(f.Context()))
+	})
+}
+
+func BenchmarkCleanup(b *testing.B) {
+	b.Cleanup(func() {
+		drop(b.Context()) // fix: drop(context.WithoutCancel(b.Context()))
+	})
+}
@@ -0,0 +1,9 @@
+package cleanup_test
+
+import "testing"
+
+func TestCleanupImport(t *testing.T) {
+	t.Cleanup(func() {
+		drop(t.Context()) // fix: drop(context.WithoutCancel(t.Context()))
+	})
+}
@@ -0,0 +1,94 @@
+package ctxparam_test
+
+import (
+	"context"
+	"testing"
+)
+
+func example(context.Context) {}
+
+func seed(t *testing.T, ctx context.Context) {
+	example(context.Background()) // fix: example(ctx)
+	example(t.Context())
+}
+
+func mustDo(t *testing.T, fn func(context.Context) error) {
+	if err := fn(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClosure(t *testing.T) {
+	seed(t, t.Context())
+
+	mustDo(t, func(ctx context.Context) error {
+		example(context.TODO()) // fix: example(ctx)
+
+		go func() {
+			example(context.TODO()) // fix: example(ctx)
+		}()
+
+		return nil
+	})
+
+	mustDo(t, func(context.Context) error {
+		example(context.TODO()) // fix: example(t.Context())
+
+		return nil
+	})
+
+	mustDo(t, func(_ context.Context) error {
+		example(context.TODO()) // fix: example(t.Context())
+
+		return nil
+	})
+
+	example(context.Background()) // fix: example(t.Context())
+}
+
+func TestShadowed(t *testing.T) {
+	mustDo(t, func(ctx context.Context) error {
+		{
+			ctx := "shadowed"
+			_ = ctx
+
+			example(context.TODO()) // fix: example(t.Context())
+		}
+
+		return nil
+	})
+}
+
+func TestInnermost(t *testing.T) {
+	mustDo(t, func(outer context.Context) error {
+		mustDo(t, func(inner context.Context) error {
+			example(context.TODO()) // fix: example(inner)
+
+			return nil
+		})
+
+		example(context.TODO()) // fix: example(outer)
+
+		return nil
+	})
+}
+
+func TestSubtest(t *testing.T) {
+	mustDo(t, func(ctx context.Context) error {
+		t.Run("sub", func(t *testing.T) {
+			example(context.TODO()) // fix: example(t.Context())
+		})
+
+		return nil
+	})
+}
+
+func TestCleanup(t *testing.T) {
+	mustDo(t, func(ctx context.Context) error {
+		t.Cleanup(func() {
+			example(context.TODO()) // fix: example(context.WithoutCancel(ctx))
+		})
+
+		return nil
+	})
+}
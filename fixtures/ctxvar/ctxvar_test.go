@@ -0,0 +1,113 @@
+package ctxvar_test
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func example(context.Context) {}
+
+func TestVariable(t *testing.T) {
+	example(context.Background()) // fix: example(t.Context())
+
+	ctx := t.Context()
+	example(ctx)
+	example(context.Background()) // fix: example(ctx)
+
+	go func() {
+		example(context.TODO()) // fix: example(ctx)
+	}()
+}
+
+func TestDerived(t *testing.T) {
+	ctx, cancel := context.WithTimeout(t.Context(), time.Minute)
+	defer cancel()
+
+	valueCtx := context.WithValue(ctx, struct{}{}, "value")
+	example(valueCtx)
+
+	example(context.Background()) // fix: example(valueCtx)
+}
+
+func TestParamDerived(t *testing.T) {
+	run := func(ctx context.Context) {
+		timeoutCtx, cancel := context.WithTimeout(ctx, time.Minute)
+		defer cancel()
+
+		example(timeoutCtx)
+		example(context.TODO()) // fix: example(timeoutCtx)
+	}
+
+	run(t.Context())
+}
+
+func TestShadowed(t *testing.T) {
+	ctx := t.Context()
+	example(ctx)
+
+	{
+		ctx := "shadowed"
+		_ = ctx
+
+		example(context.Background()) // fix: example(t.Context())
+	}
+
+	example(context.Background()) // fix: example(ctx)
+}
+
+func TestBlock(t *testing.T) {
+	{
+		ctx := t.Context()
+		example(ctx)
+	}
+
+	example(context.Background()) // fix: example(t.Context())
+}
+
+func TestReassigned(t *testing.T) {
+	ctx := t.Context()
+	example(ctx)
+
+	example(context.Background()) // fix: example(t.Context())
+
+	ctx = context.WithValue(ctx, struct{}{}, "value")
+	example(ctx)
+}
+
+func TestCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	example(ctx)
+	cancel()
+
+	example(context.Background()) // fix: example(t.Context())
+}
+
+func TestOtherRoot(t *testing.T) {
+	ctx := context.WithValue(context.Background(), struct{}{}, "value") // fix: ctx := context.WithValue(t.Context(), struct{}{}, "value")
+	example(ctx)
+
+	example(context.Background()) // fix: example(t.Context())
+}
+
+func TestSubtest(t *testing.T) {
+	ctx := t.Context()
+	example(ctx)
+
+	t.Run("sub", func(t *testing.T) {
+		example(context.Background()) // fix: example(t.Context())
+
+		subCtx := t.Context()
+		example(subCtx)
+		example(context.Background()) // fix: example(subCtx)
+	})
+}
+
+func TestCleanup(t *testing.T) {
+	ctx := t.Context()
+	example(ctx)
+
+	t.Cleanup(func() {
+		example(context.Background()) // fix: example(context.WithoutCancel(ctx))
+	})
+}
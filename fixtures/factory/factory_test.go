@@ -0,0 +1,80 @@
+package factory_test
+
+import (
+	"context"
+	"testing"
+)
+
+func example(context.Context) {}
+
+type testCase struct {
+	name string
+}
+
+func makeCase(t *testing.T, tc testCase) func(*testing.T) {
+	return func(t2 *testing.T) {
+		example(t.Context())          // fix: example(t2.Context())
+		example(context.Background()) // fix: example(t2.Context())
+	}
+}
+
+func makeCaseIndirect(t *testing.T, tc testCase) func(*testing.T) {
+	run := func(t2 *testing.T) {
+		example(t.Context()) // fix: example(t2.Context())
+	}
+
+	return run
+}
+
+func (tc testCase) runner(t *testing.T) func(*testing.T) {
+	return func(t2 *testing.T) {
+		example(t.Context()) // fix: example(t2.Context())
+	}
+}
+
+func (tc testCase) valueRunner(t *testing.T) func(*testing.T) {
+	return func(t2 *testing.T) {
+		example(t.Context()) // fix: example(t2.Context())
+	}
+}
+
+func makeWrapped(t *testing.T, tc testCase) func(*testing.T) {
+	return func(t2 *testing.T) {
+		check := func() {
+			example(t.Context())          // fix: example(t2.Context())
+			example(context.Background()) // fix: example(t2.Context())
+		}
+
+		check()
+	}
+}
+
+func TestFactory(t *testing.T) {
+	tests := []testCase{{name: "a"}, {name: "b"}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, makeCase(t, tc))
+		t.Run(tc.name, makeCaseIndirect(t, tc))
+		t.Run(tc.name, tc.runner(t))
+		t.Run(tc.name, makeWrapped(t, tc))
+
+		mk := tc.valueRunner
+		t.Run(tc.name, mk(t))
+	}
+}
+
+func TestLocalVar(t *testing.T) {
+	sub := func(t2 *testing.T) {
+		example(t.Context()) // fix: example(t2.Context())
+	}
+
+	t.Run("sub", sub)
+}
+
+func TestNotRun(t *testing.T) {
+	helper := func(tb testing.TB) {
+		example(t.Context())
+	}
+
+	helper(t)
+}
@@ -0,0 +1,10 @@
+// Package facts is a fixture for functions creating root contexts which are
+// called from tests.
+package facts
+
+import "github.com/icedream/testctxlint/fixtures/facts/testutil"
+
+// newLocalClient creates a client through testutil.
+func newLocalClient() *testutil.Client {
+	return testutil.NewDefaultClient()
+}
@@ -0,0 +1,30 @@
+package facts
+
+import (
+	"testing"
+
+	"github.com/icedream/testctxlint/fixtures/facts/testutil"
+)
+
+func TestClient(t *testing.T) {
+	client := testutil.NewClient() // want-nofix
+	defer client.Close()
+
+	testutil.NewDefaultClient() // want-nofix
+	testutil.NewClientContext(t.Context())
+	testutil.Dial(t.Context())
+	testutil.NewTestClient(t)
+
+	client.Reset() // want-nofix
+	testutil.Lazy()
+
+	newLocalClient() // want-nofix
+
+	t.Cleanup(func() {
+		testutil.NewClient().Close()
+	})
+}
+
+func setup() *testutil.Client {
+	return testutil.NewClient()
+}
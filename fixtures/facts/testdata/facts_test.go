@@ -0,0 +1,19 @@
+package facts
+
+import (
+	"context"
+	"testing"
+
+	"example.com/stub"
+)
+
+func open() {
+	_ = context.Background()
+}
+
+func TestOtherModule(t *testing.T) {
+	open() // want-nofix
+
+	// Functions of other modules cannot be changed to take a context
+	stub.Open()
+}
@@ -0,0 +1,7 @@
+module example.com/facts
+
+go 1.24
+
+require example.com/stub v0.0.0
+
+replace example.com/stub => ./stub
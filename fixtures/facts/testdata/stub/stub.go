@@ -0,0 +1,10 @@
+// Package stub is a stub of a dependency in another module, which creates
+// its own root context.
+package stub
+
+import "context"
+
+// Open opens a connection with its own root context.
+func Open() {
+	_ = context.Background()
+}
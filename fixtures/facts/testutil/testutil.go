@@ -0,0 +1,65 @@
+// Package testutil is a stub of a package with helpers for tests.
+package testutil
+
+import (
+	"context"
+	"testing"
+)
+
+// Client is a stub of a client connected to some service.
+type Client struct {
+	ctx context.Context
+}
+
+func dial(ctx context.Context) *Client {
+	return &Client{ctx: ctx}
+}
+
+// NewClient creates a client with its own root context.
+func NewClient() *Client {
+	return dial(context.Background())
+}
+
+// NewDefaultClient creates a client through NewClient.
+func NewDefaultClient() *Client {
+	return NewClient()
+}
+
+// NewClientContext creates a client with the given context.
+func NewClientContext(ctx context.Context) *Client {
+	return dial(ctx)
+}
+
+// Dial creates a client with the given context, falling back to a root
+// context without one.
+func Dial(ctx context.Context) *Client {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return dial(ctx)
+}
+
+// NewTestClient creates a client for the test tb. The root context it
+// creates is reported within it already.
+func NewTestClient(tb testing.TB) *Client {
+	return dial(context.Background())
+}
+
+// Close closes the client.
+func (c *Client) Close() {
+	_ = c.ctx
+}
+
+// Reset resets the client with a new root context.
+func (c *Client) Reset() {
+	c.ctx = context.TODO()
+}
+
+// Lazy returns a function creating a client, which is not called by Lazy
+// itself.
+func Lazy() func() *Client {
+	return func() *Client {
+		return dial(context.Background())
+	}
+}
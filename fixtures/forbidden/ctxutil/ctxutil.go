@@ -0,0 +1,31 @@
+// Package ctxutil is a stub of a project-specific package creating contexts.
+package ctxutil
+
+import "context"
+
+// NewRoot returns a new root context.
+func NewRoot() context.Context {
+	return context.Background()
+}
+
+// BackgroundWithTracer returns a new root context with a named tracer.
+func BackgroundWithTracer(name string) context.Context {
+	return context.WithValue(context.Background(), tracerKey{}, name)
+}
+
+// Wrap returns a context derived from ctx.
+func Wrap(ctx context.Context) context.Context {
+	return context.WithValue(ctx, tracerKey{}, "")
+}
+
+// WithTracer returns a context derived from ctx with a named tracer.
+func WithTracer(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, tracerKey{}, name)
+}
+
+type tracerKey struct{}
+
+// NewTestRoot returns a new root context for tests.
+func NewTestRoot() context.Context {
+	return context.Background()
+}
@@ -0,0 +1,30 @@
+package forbidden_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/icedream/testctxlint/fixtures/forbidden/ctxutil"
+)
+
+func example(context.Context) {}
+
+func TestNewRoot(t *testing.T) {
+	example(ctxutil.NewRoot()) // fix: example(ctxutil.Wrap(t.Context()))
+}
+
+func TestBackgroundWithTracer(t *testing.T) {
+	example(ctxutil.BackgroundWithTracer("test")) // fix: example(ctxutil.WithTracer(t.Context(), "test"))
+}
+
+func TestStandard(t *testing.T) {
+	example(context.Background()) // fix: example(t.Context())
+}
+
+func TestAllowed(t *testing.T) {
+	example(ctxutil.Wrap(t.Context()))
+}
+
+func helper() context.Context {
+	return ctxutil.NewRoot()
+}
@@ -0,0 +1,11 @@
+package forbidden_test
+
+import (
+	"testing"
+
+	"github.com/icedream/testctxlint/fixtures/forbidden/ctxutil"
+)
+
+func TestPlain(t *testing.T) {
+	example(ctxutil.NewTestRoot()) // fix: example(t.Context())
+}
@@ -0,0 +1,11 @@
+package forbidden_test
+
+import (
+	"testing"
+
+	"github.com/icedream/testctxlint/fixtures/forbidden/ctxutil"
+)
+
+func TestReplace(t *testing.T) {
+	example(ctxutil.NewRoot()) // fix: example(ctxutil.Wrap(t.Context()))
+}
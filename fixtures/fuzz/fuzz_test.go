@@ -0,0 +1,60 @@
+package fuzz_test
+
+import (
+	"context"
+	"testing"
+)
+
+func example(ctx context.Context) {
+	go func() {
+		<-ctx.Done()
+	}()
+}
+
+func seed(ctx context.Context) string {
+	example(ctx)
+
+	return "seed"
+}
+
+func FuzzParse(f *testing.F) {
+	ctx := context.Background() // fix: ctx := f.Context()
+	example(ctx)
+
+	f.Add(seed(context.TODO())) // fix: f.Add(seed(f.Context()))
+
+	f.Fuzz(func(t *testing.T, data string) {
+		example(context.Background()) // fix: example(t.Context())
+
+		t.Run("sub", func(t2 *testing.T) {
+			example(context.TODO()) // fix: example(t2.Context())
+		})
+
+		go func() {
+			example(context.Background()) // fix: example(t.Context())
+		}()
+	})
+}
+
+func FuzzGoroutine(f *testing.F) {
+	go func() {
+		example(context.Background()) // fix: example(f.Context())
+	}()
+
+	f.Fuzz(func(*testing.T, []byte) {
+		example(context.TODO()) // fix: example(t.Context())
+	})
+}
+
+func addSeedsUnnamed(*testing.F) {
+	example(context.Background()) // fix: example(f.Context())
+}
+
+func FuzzDolem(f *testing.F) {
+	addSeedsUnnamed(f)
+	f.Add(seed(f.Context()))
+
+	f.Fuzz(func(t *testing.T, data string) {
+		example(t.Context())
+	})
+}
@@ -0,0 +1,67 @@
+package generic_test
+
+import (
+	"context"
+	"testing"
+)
+
+func example(ctx context.Context) {
+	go func() {
+		<-ctx.Done()
+	}()
+}
+
+func mustDo[T testing.TB](t T, fn func(context.Context) error) {
+	t.Helper()
+
+	if err := fn(context.Background()); err != nil { // fix: if err := fn(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func run[S interface {
+	testing.TB
+	Parallel()
+}](s S) {
+	s.Parallel()
+
+	example(context.TODO()) // fix: example(s.Context())
+}
+
+func runUnnamed[T testing.TB](T) {
+	example(context.Background()) // fix: example(tb.Context())
+}
+
+type parallelTB interface {
+	testing.TB
+	Parallel()
+}
+
+func runNamedConstraint[S parallelTB](s S) {
+	go func() {
+		example(context.Background()) // fix: example(s.Context())
+	}()
+}
+
+type contexter interface {
+	Context() context.Context
+}
+
+// notTesting has a Context method, but it is not the one from testing.TB.
+func notTesting[C contexter](c C) {
+	example(context.Background())
+	example(c.Context())
+}
+
+func unconstrained[T any](T) {
+	example(context.Background())
+}
+
+func TestGeneric(t *testing.T) {
+	mustDo(t, func(context.Context) error { return nil })
+	run(t)
+	runUnnamed(t)
+	runNamedConstraint(t)
+	notTesting(t)    // want-nofix
+	unconstrained(t) // want-nofix
+}
@@ -0,0 +1,67 @@
+package ginkgo_test
+
+import (
+	"context"
+
+	. "github.com/icedream/testctxlint/fixtures/ginkgo/stub/ginkgo"
+	"github.com/icedream/testctxlint/fixtures/ginkgo/stub/gomega"
+)
+
+func example(ctx context.Context) error {
+	go func() {
+		<-ctx.Done()
+	}()
+
+	return nil
+}
+
+var _ = Describe("client", func() {
+	// Container bodies run while the spec tree is built, outside of any spec
+	_ = example(context.Background())
+
+	BeforeEach(func() {
+		_ = example(context.Background()) // fix: _ = example(GinkgoT().Context())
+	})
+
+	AfterEach(func(ctx SpecContext) {
+		_ = example(context.TODO()) // fix: _ = example(ctx)
+	})
+
+	It("does x", func(ctx SpecContext) {
+		_ = example(context.Background()) // fix: _ = example(ctx)
+
+		go func() {
+			_ = example(context.TODO()) // fix: _ = example(ctx)
+		}()
+	})
+
+	It("does y", func() {
+		_ = example(context.Background()) // fix: _ = example(GinkgoT().Context())
+
+		gomega.Eventually(func(ctx context.Context) error {
+			return example(context.Background()) // fix: return example(ctx)
+		}).Should(gomega.Succeed())
+
+		gomega.Eventually(func() error {
+			return example(context.TODO()) // fix: return example(GinkgoT().Context())
+		}).Should(gomega.Succeed())
+	})
+
+	It("does z", func(SpecContext) {
+		_ = example(context.Background()) // fix: _ = example(ctx)
+	})
+
+	DescribeTable("table",
+		func(a, b int) {
+			_ = example(context.Background()) // fix: _ = example(GinkgoT().Context())
+		},
+		Entry("one", 1, 2),
+	)
+
+	DescribeTable("interruptible table",
+		func(ctx context.Context, a int) {
+			_ = example(context.TODO()) // fix: _ = example(ctx)
+		},
+		Entry("one", 1),
+	)
+})
@@ -0,0 +1,13 @@
+package ginkgo_test
+
+import (
+	"context"
+
+	g "github.com/icedream/testctxlint/fixtures/ginkgo/stub/ginkgo"
+)
+
+var _ = g.Describe("qualified", func() {
+	g.It("does x", func() {
+		_ = example(context.Background()) // fix: _ = example(g.GinkgoT().Context())
+	})
+})
@@ -0,0 +1,44 @@
+// Package ginkgo is a stand-in for the parts of github.com/onsi/ginkgo/v2
+// relevant to the analyzer. Its functions never run any specs.
+package ginkgo
+
+import "context"
+
+// SpecContext is the context passed to interruptible spec bodies.
+type SpecContext interface {
+	context.Context
+
+	SpecReport() any
+}
+
+// GinkgoTInterface is implemented by the value returned by GinkgoT.
+type GinkgoTInterface interface {
+	Context() context.Context
+	Helper()
+}
+
+// GinkgoT returns a testing.T-like handle for the current spec.
+func GinkgoT(...int) GinkgoTInterface {
+	return nil
+}
+
+// TableEntry is an entry of a table.
+type TableEntry struct{}
+
+// Describe declares a container.
+func Describe(string, ...any) bool { return true }
+
+// It declares a spec.
+func It(string, ...any) bool { return true }
+
+// BeforeEach declares a setup node.
+func BeforeEach(...any) bool { return true }
+
+// AfterEach declares a teardown node.
+func AfterEach(...any) bool { return true }
+
+// DescribeTable declares a table of specs.
+func DescribeTable(string, ...any) bool { return true }
+
+// Entry declares an entry of a table.
+func Entry(any, ...any) TableEntry { return TableEntry{} }
@@ -0,0 +1,60 @@
+package hoist_test
+
+import (
+	"context"
+	"testing"
+)
+
+func example(context.Context) {}
+
+func TestHoist(t *testing.T) {
+	example(context.Background()) // fix: example(ctx)
+
+	go func() {
+		example(context.TODO()) // fix: example(ctx)
+	}()
+
+	example(context.Background()) // fix: example(ctx)
+}
+
+func TestTaken(t *testing.T) {
+	example(context.Background()) // fix: example(ctx2)
+
+	{
+		ctx := "taken"
+		_ = ctx
+	}
+}
+
+func TestExisting(t *testing.T) {
+	ctx := t.Context()
+	example(ctx)
+
+	example(context.Background()) // fix: example(ctx)
+}
+
+func TestSubtest(t *testing.T) {
+	t.Run("sub", func(t *testing.T) {
+		example(context.Background()) // fix: example(ctx)
+	})
+}
+
+func TestUnnamed(*testing.T) {
+	example(context.Background()) // fix: example(ctx)
+	example(context.TODO())       // fix: example(ctx)
+}
+
+func TestCleanup(t *testing.T) {
+	t.Cleanup(func() {
+		example(context.Background()) // fix: example(context.WithoutCancel(ctx))
+	})
+}
+
+func TestCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background()) // fix: ctx := t.Context()
+	defer cancel()
+
+	example(ctx)
+}
+
+func TestOneLine(t *testing.T) { example(context.Background()) } // fix: func TestOneLine(t *testing.T) { example(t.Context()) }
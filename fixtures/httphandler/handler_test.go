@@ -0,0 +1,72 @@
+package httphandler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func example(context.Context) {}
+
+func TestHandlerFunc(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		example(context.Background()) // fix: example(r.Context())
+
+		go func() {
+			example(context.TODO()) // fix: example(r.Context())
+		}()
+	}))
+	defer srv.Close()
+
+	example(context.Background()) // fix: example(t.Context())
+}
+
+func TestHandleFunc(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(_ http.ResponseWriter, req *http.Request) {
+		ctx := req.Context()
+		example(ctx)
+		example(context.Background()) // fix: example(ctx)
+	})
+}
+
+func TestUnnamed(t *testing.T) {
+	var handler http.HandlerFunc = func(http.ResponseWriter, *http.Request) {
+		example(context.TODO()) // fix: example(r.Context())
+	}
+
+	_ = handler
+}
+
+func TestRequestParam(t *testing.T) {
+	check := func(r *http.Request) error {
+		example(context.TODO()) // fix: example(r.Context())
+
+		return nil
+	}
+
+	_ = check
+}
+
+func TestBlankRequest(t *testing.T) {
+	_ = http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})
+	_ = http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+		example(context.TODO()) // fix: example(r.Context())
+	})
+}
+
+func handler(w http.ResponseWriter, r *http.Request) {
+	example(context.Background())
+}
+
+func newHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		example(context.Background())
+	})
+}
+
+func TestHandlers(t *testing.T) {
+	_ = http.HandlerFunc(handler)
+	_ = newHandler()
+}
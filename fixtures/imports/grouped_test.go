@@ -0,0 +1,15 @@
+package imports_test
+
+import (
+	"context"
+	"testing"
+)
+
+func TestGrouped(t *testing.T) {
+	ctx := context.Background() // fix: ctx := t.Context()
+	example(ctx)
+
+	t.Run("sub", func(t2 *testing.T) {
+		example(context.TODO()) // fix: example(t2.Context())
+	})
+}
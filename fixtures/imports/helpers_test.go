@@ -0,0 +1,9 @@
+package imports_test
+
+import "context"
+
+func example(ctx context.Context) {
+	go func() {
+		<-ctx.Done()
+	}()
+}
@@ -0,0 +1,9 @@
+package imports_test
+
+import "context"
+
+import "testing"
+
+func TestSingle(t *testing.T) {
+	example(context.TODO()) // fix: example(t.Context())
+}
@@ -0,0 +1,22 @@
+package imports_test
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestStillUsed(t *testing.T) {
+	example(context.Background()) // fix: example(t.Context())
+
+	ctx, cancel := context.WithTimeout(t.Context(), time.Second)
+	defer cancel()
+
+	example(ctx)
+}
+
+func TestCleanup(t *testing.T) {
+	t.Cleanup(func() {
+		example(context.Background()) // fix: example(context.WithoutCancel(t.Context()))
+	})
+}
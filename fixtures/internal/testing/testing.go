@@ -0,0 +1,18 @@
+// Package testing is an in-house package that happens to share its name with
+// the standard library's testing package.
+package testing
+
+import "context"
+
+// T is an in-house type unrelated to the standard library's testing.T.
+type T struct{}
+
+// Context returns a background context.
+func (*T) Context() context.Context {
+	return context.Background()
+}
+
+// TB is an in-house type unrelated to the standard library's testing.TB.
+type TB interface {
+	Context() context.Context
+}
@@ -0,0 +1,52 @@
+package naming_test
+
+import (
+	"context"
+	"testing"
+)
+
+var b = "taken by a package-level variable"
+
+func example(context.Context) {}
+
+func TestBlank(_ *testing.T) {
+	example(context.Background()) // fix: example(t.Context())
+	example(context.TODO())       // fix: example(t.Context())
+}
+
+func TestLocal(*testing.T) {
+	t := "taken by a local variable"
+	_ = t
+
+	example(context.Background()) // fix: example(t2.Context())
+}
+
+func TestNestedBlock(*testing.T) {
+	example(context.Background()) // fix: example(t2.Context())
+
+	{
+		t := "taken within a block"
+		_ = t
+	}
+}
+
+func BenchmarkPackageLevel(*testing.B) {
+	example(context.Background()) // fix: example(b2.Context())
+}
+
+func FuzzMixed(f *testing.F) {
+	f.Fuzz(func(*testing.T, []byte, string) {
+		example(context.TODO()) // fix: example(t.Context())
+		example(context.TODO()) // fix: example(t.Context())
+	})
+}
+
+func helper(testing.TB) {
+	example(context.TODO()) // fix: example(tb.Context())
+
+	run(func(tb testing.TB) {
+		example(tb.Context())
+	})
+}
+
+func run(func(testing.TB)) {}
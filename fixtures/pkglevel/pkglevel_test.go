@@ -0,0 +1,37 @@
+package pkglevel_test
+
+import (
+	"context"
+	"testing"
+)
+
+func example(context.Context) {}
+
+// ctx is shared by the tests.
+var ctx = context.Background() // fix: <removed>
+
+func TestSharedA(t *testing.T) {
+	example(ctx)
+}
+
+func TestSharedB(t *testing.T) {
+	t.Run("sub", func(t2 *testing.T) {
+		example(ctx)
+	})
+}
+
+var tests = []struct {
+	name string
+	ctx  context.Context
+}{
+	{name: "a", ctx: context.Background()}, // fix: {name: "a", ctx: func(tb testing.TB) context.Context { return tb.Context() }},
+	{name: "b", ctx: context.TODO()},       // fix: {name: "b", ctx: func(tb testing.TB) context.Context { return tb.Context() }},
+}
+
+func TestTable(t *testing.T) {
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			example(tc.ctx)
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package pkglevel_test
+
+import (
+	"context"
+	"testing"
+)
+
+var background = context.Background() // want-nofix
+
+func helper() {
+	example(background)
+}
+
+func TestHelper(t *testing.T) {
+	helper()
+	example(background)
+}
+
+var mixed = []struct {
+	ctx context.Context
+}{
+	{ctx: context.TODO()}, // want-nofix
+	{ctx: background},
+}
+
+func TestMixed(t *testing.T) {
+	for _, tc := range mixed {
+		example(tc.ctx)
+	}
+}
@@ -0,0 +1,26 @@
+// Package bdd is a stand-in for a homegrown BDD test runner.
+package bdd
+
+import (
+	"context"
+	"testing"
+)
+
+// S is the handle of a running scenario.
+type S struct {
+	t *testing.T
+}
+
+// Context returns the context of the running scenario.
+func (s *S) Context() context.Context {
+	return s.t.Context()
+}
+
+// Scenario runs fn as a scenario of the test t.
+func Scenario(t *testing.T, name string, fn func(s *S)) {
+	t.Helper()
+
+	t.Run(name, func(t *testing.T) {
+		fn(&S{t})
+	})
+}
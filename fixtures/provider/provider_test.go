@@ -0,0 +1,33 @@
+package provider_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/icedream/testctxlint/fixtures/provider/bdd"
+)
+
+func example(ctx context.Context) {
+	go func() {
+		<-ctx.Done()
+	}()
+}
+
+func TestScenario(t *testing.T) {
+	example(context.Background()) // fix: example(t.Context())
+
+	bdd.Scenario(t, "scenario", func(s *bdd.S) {
+		example(context.Background()) // fix: example(s.Context())
+
+		go func() {
+			example(context.TODO()) // fix: example(s.Context())
+		}()
+
+		example(s.Context())
+	})
+
+	// Not a scenario, so t is still the nearest test handle
+	func(s *bdd.S) {
+		example(context.TODO()) // fix: example(t.Context())
+	}(nil)
+}
@@ -0,0 +1,59 @@
+package refactor_test
+
+import (
+	"context"
+	"testing"
+)
+
+type client struct {
+	ctx context.Context
+}
+
+func dial(ctx context.Context) *client {
+	return &client{ctx: ctx}
+}
+
+func newClient() *client {
+	return dial(context.Background())
+}
+
+func newNamedClient(name string) *client {
+	c := newClient()
+	_ = name
+
+	return c
+}
+
+func TestRefactor(t *testing.T) {
+	newClient()         // fix: newClient(t.Context())
+	newNamedClient("a") // fix: newNamedClient(t.Context(), "a")
+
+	t.Run("sub", func(t2 *testing.T) {
+		newClient() // fix: newClient(t2.Context())
+	})
+
+	t.Cleanup(func() {
+		newClient()
+	})
+}
+
+func BenchmarkRefactor(*testing.B) {
+	newClient() // fix: newClient(b.Context())
+}
+
+// NewExported may be called by other packages.
+func NewExported() *client {
+	return dial(context.TODO())
+}
+
+func withValue() *client {
+	return dial(context.TODO())
+}
+
+var withValueFunc = withValue
+
+func TestUnrefactorable(t *testing.T) {
+	NewExported() // want-nofix
+	withValue()   // want-nofix
+	withValueFunc()
+}
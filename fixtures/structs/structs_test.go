@@ -0,0 +1,113 @@
+package structs_test
+
+import (
+	"context"
+	"testing"
+)
+
+func example(ctx context.Context) {
+	go func() {
+		<-ctx.Done()
+	}()
+}
+
+type db struct{}
+
+type fixture struct {
+	t  *testing.T
+	db *db
+}
+
+func (f *fixture) seed() {
+	example(context.Background()) // fix: example(f.t.Context())
+
+	go func() {
+		example(context.TODO()) // fix: example(f.t.Context())
+	}()
+}
+
+func (f fixture) seedValue() {
+	example(context.Background()) // fix: example(f.t.Context())
+}
+
+func (*fixture) seedUnnamed() {
+	example(context.Background()) // fix: example(f.t.Context())
+}
+
+// seedWithParam prefers its own testing parameter over the receiver's.
+func (f *fixture) seedWithParam(t2 *testing.T) {
+	example(context.Background()) // fix: example(t2.Context())
+}
+
+type embeddingFixture struct {
+	*testing.T
+}
+
+func (f *embeddingFixture) seed() {
+	example(context.Background()) // fix: example(f.Context())
+}
+
+type embeddingTBFixture struct {
+	testing.TB
+}
+
+func (f *embeddingTBFixture) seed() {
+	example(context.TODO()) // fix: example(f.Context())
+}
+
+type nestedFixture struct {
+	fixture
+}
+
+func (n *nestedFixture) seed() {
+	example(context.Background()) // fix: example(n.t.Context())
+}
+
+type benchFixture struct {
+	name string
+	b    *testing.B
+}
+
+func (f *benchFixture) seed() {
+	example(context.Background()) // fix: example(f.b.Context())
+}
+
+type ownContextFixture struct {
+	*testing.T
+	tb testing.TB
+}
+
+// Context shadows the Context method promoted from the embedded *testing.T.
+func (f *ownContextFixture) Context() context.Context {
+	return f.tb.Context()
+}
+
+func (f *ownContextFixture) seed() {
+	example(context.Background()) // fix: example(f.tb.Context())
+}
+
+type noTestingFixture struct {
+	db *db
+}
+
+func (f *noTestingFixture) seed() {
+	example(context.Background())
+}
+
+func TestFixture(t *testing.T) {
+	f := &fixture{t: t}
+	f.seed()
+	f.seedValue()
+	f.seedUnnamed()
+	f.seedWithParam(t)
+
+	(&embeddingFixture{t}).seed()
+	(&embeddingTBFixture{t}).seed()
+	(&nestedFixture{*f}).seed()
+	(&ownContextFixture{t, t}).seed()
+	(&noTestingFixture{}).seed() // want-nofix
+}
+
+func BenchmarkFixture(b *testing.B) {
+	(&benchFixture{b: b}).seed()
+}
@@ -0,0 +1,14 @@
+// Package harness provides test handles wrapping *testing.T.
+package harness
+
+import "testing"
+
+// T wraps *testing.T.
+type T struct {
+	*testing.T
+}
+
+// Ctx is not allow-listed even though it has a Context method.
+type Ctx struct {
+	T
+}
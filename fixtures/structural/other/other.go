@@ -0,0 +1,12 @@
+// Package other is not allow-listed.
+package other
+
+import "context"
+
+// Env has a Context method, but is not allow-listed.
+type Env struct{}
+
+// Context returns a background context.
+func (Env) Context() context.Context {
+	return context.Background()
+}
@@ -0,0 +1,55 @@
+package structural_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/icedream/testctxlint/fixtures/structural/harness"
+	"github.com/icedream/testctxlint/fixtures/structural/other"
+	"github.com/icedream/testctxlint/fixtures/structural/testenv"
+)
+
+func example(ctx context.Context) {
+	go func() {
+		<-ctx.Done()
+	}()
+}
+
+func withEnv(env *testenv.Env) {
+	example(context.Background()) // fix: example(env.Context())
+
+	go func() {
+		example(context.TODO()) // fix: example(env.Context())
+	}()
+}
+
+func withUnnamedEnv(*testenv.Env) {
+	example(context.Background()) // fix: example(env.Context())
+}
+
+func withHarness(ht harness.T) {
+	example(context.Background()) // fix: example(ht.Context())
+}
+
+func withNotAllowed(c harness.Ctx, e other.Env) {
+	example(context.Background())
+}
+
+// withBoth prefers the testing handle over the wrapper.
+func withBoth(env *testenv.Env, t *testing.T) {
+	example(context.Background()) // fix: example(t.Context())
+}
+
+func TestStructural(t *testing.T) {
+	env := testenv.New(t)
+
+	withEnv(env)
+	withUnnamedEnv(env)
+	withHarness(harness.T{T: t})
+	withNotAllowed(harness.Ctx{T: harness.T{T: t}}, other.Env{}) // want-nofix
+	withBoth(env, t)
+
+	func(env *testenv.Env) {
+		example(context.Background()) // fix: example(env.Context())
+	}(env)
+}
@@ -0,0 +1,24 @@
+// Package testenv provides a test environment wrapping *testing.T.
+package testenv
+
+import (
+	"context"
+	"testing"
+)
+
+// Env is a test environment.
+type Env struct {
+	t *testing.T
+}
+
+// New returns a test environment for t.
+func New(t *testing.T) *Env {
+	t.Helper()
+
+	return &Env{t}
+}
+
+// Context returns the context of the test.
+func (e *Env) Context() context.Context {
+	return e.t.Context()
+}
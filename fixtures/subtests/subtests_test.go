@@ -0,0 +1,93 @@
+package subtests_test
+
+import (
+	"context"
+	"testing"
+)
+
+func example(context.Context) {}
+
+func TestParentCall(t *testing.T) {
+	t.Run("sub", func(t2 *testing.T) {
+		example(t.Context()) // fix: example(t2.Context())
+		example(t2.Context())
+		example((t.Context)()) // fix: example(t2.Context())
+
+		go func() {
+			example(t.Context()) // fix: example(t2.Context())
+		}()
+
+		t2.Run("subsub", func(t3 *testing.T) {
+			example(t.Context())  // fix: example(t3.Context())
+			example(t2.Context()) // fix: example(t3.Context())
+		})
+	})
+
+	example(t.Context())
+}
+
+func TestParentVar(t *testing.T) {
+	ctx := t.Context()
+	example(ctx)
+
+	t.Run("sub", func(t *testing.T) {
+		example(ctx) // fix: example(t.Context())
+
+		ctx := t.Context()
+		example(ctx)
+	})
+
+	t.Run("assigned", func(t *testing.T) {
+		ctx = t.Context()
+		example(ctx)
+	})
+}
+
+func TestParentVarDeclared(t *testing.T) {
+	var ctx = t.Context()
+
+	t.Run("sub", func(*testing.T) {
+		example(ctx) // want-nofix
+	})
+}
+
+func TestParentVarShared(t *testing.T) {
+	ctx := context.Background() // fix: ctx := t.Context()
+
+	t.Run("first", func(t *testing.T) {
+		example(ctx) // want-nofix
+	})
+
+	t.Run("second", func(t *testing.T) {
+		example(ctx) // want-nofix
+	})
+}
+
+func TestParentDerived(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	t.Run("sub", func(*testing.T) {
+		example(ctx)
+	})
+}
+
+func TestParentShadowed(t *testing.T) {
+	t.Run("sub", func(t *testing.T) {
+		example(t.Context())
+	})
+}
+
+func TestParentCleanup(t *testing.T) {
+	t.Run("sub", func(t2 *testing.T) {
+		t2.Cleanup(func() {
+			example(context.WithoutCancel(t.Context()))
+		})
+	})
+}
+
+func TestParentGoroutine(t *testing.T) {
+	go func(t2 *testing.T) {
+		example(t.Context())
+	}(t)
+}
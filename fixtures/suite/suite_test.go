@@ -0,0 +1,88 @@
+package suite_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+func example(ctx context.Context) {
+	go func() {
+		<-ctx.Done()
+	}()
+}
+
+type MySuite struct {
+	suite.Suite
+}
+
+func (s *MySuite) SetupSuite() {
+	example(context.Background()) // fix: example(s.T().Context())
+}
+
+func (s *MySuite) TearDownSuite() {
+	example(context.TODO()) // fix: example(s.T().Context())
+}
+
+func (s *MySuite) SetupTest() {
+	example(context.Background()) // fix: example(s.T().Context())
+}
+
+func (s *MySuite) TearDownTest() {
+	example(s.T().Context())
+}
+
+func (s *MySuite) SetupSubTest() {
+	example(context.TODO()) // fix: example(s.T().Context())
+}
+
+func (s *MySuite) TestX() {
+	example(context.Background()) // fix: example(s.T().Context())
+
+	s.Run("sub", func() {
+		example(context.TODO()) // fix: example(s.T().Context())
+	})
+
+	go func() {
+		example(context.Background()) // fix: example(s.T().Context())
+	}()
+}
+
+func (*MySuite) TestUnnamed() {
+	example(context.Background()) // fix: example(m.T().Context())
+}
+
+func (s *MySuite) TestWithT() {
+	s.T().Run("sub", func(t *testing.T) {
+		example(context.Background()) // fix: example(t.Context())
+	})
+}
+
+// helper may be called while no test is running, so it is not a test scope.
+func (s *MySuite) helper() {
+	example(context.Background())
+}
+
+func TestMySuite(t *testing.T) {
+	(&MySuite{}).helper() // want-nofix
+
+	suite.Run(t, new(MySuite))
+}
+
+// customSuite is not based on testify, but follows the same conventions.
+type customSuite struct {
+	t *testing.T
+}
+
+func (c *customSuite) T() *testing.T {
+	return c.t
+}
+
+func (c *customSuite) TestY() {
+	example(context.TODO()) // fix: example(c.t.Context())
+}
+
+func TestCustomSuite(t *testing.T) {
+	(&customSuite{t}).TestY()
+}
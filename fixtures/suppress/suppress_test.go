@@ -0,0 +1,31 @@
+package suppress_test
+
+import (
+	"context"
+	"testing"
+)
+
+func example(context.Context) {}
+
+func TestSuppressed(t *testing.T) {
+	example(context.Background()) //testctxlint:ignore the client outlives the test
+	example(context.TODO())       //testctxlint:ignore
+	example(context.TODO())       //testctxlint:ignored directives need a space // fix: example(t.Context())       //testctxlint:ignored directives need a space
+}
+
+func TestAlternatives(t *testing.T) {
+	example(context.Background()) // fix: example(t.Context()) || example(context.WithoutCancel(t.Context())) || example(context.Background()) //testctxlint:ignore <reason>
+
+	ctx := context.TODO() // fix: ctx := t.Context() || ctx := context.WithoutCancel(t.Context()) || ctx := context.TODO() //testctxlint:ignore <reason>
+	example(ctx)
+}
+
+func TestUnnamed(*testing.T) {
+	example(context.Background()) // fix: example(t.Context()) || example(context.WithoutCancel(t.Context())) || example(context.Background()) //testctxlint:ignore <reason>
+}
+
+func TestCleanup(t *testing.T) {
+	t.Cleanup(func() {
+		example(context.Background()) // fix: example(context.WithoutCancel(t.Context())) || example(context.Background()) //testctxlint:ignore <reason>
+	})
+}
@@ -18,7 +18,7 @@ func TestLorem(t *testing.T) {
 	example(context.Background()) // fix: example(t.Context())
 
 	t.Run("test", func(t2 *testing.T) {
-		example(ctx)
+		example(ctx) // fix: example(t2.Context())
 
 		example(context.Background()) // fix: example(t2.Context())
 
@@ -41,7 +41,7 @@ func TestIpsum(t *testing.T) {
 	example(ctx)
 
 	t.Run("test", func(t2 *testing.T) {
-		example(ctx)
+		example(ctx) // fix: example(t2.Context())
 
 		example(context.TODO()) // fix: example(t2.Context())
 
@@ -84,7 +84,7 @@ func BenchmarkIpsum(b *testing.B) {
 	example(ctx)
 
 	b.Run("test", func(b2 *testing.B) {
-		example(ctx)
+		example(ctx) // fix: example(b2.Context())
 
 		example(context.Background()) // fix: example(b2.Context())
 	})
@@ -115,3 +115,26 @@ func BenchmarkUnnamedParam(*testing.B) {
 func testSubUnnamed(*testing.T) {
 	example(context.Background()) // fix: example(t.Context())
 }
+
+func newServer(tb testing.TB) {
+	tb.Helper()
+
+	example(context.Background()) // fix: example(tb.Context())
+
+	go func() {
+		example(context.TODO()) // fix: example(tb.Context())
+	}()
+}
+
+func newServerUnnamed(testing.TB) {
+	example(context.Background()) // fix: example(tb.Context())
+}
+
+func TestNewServer(t *testing.T) {
+	newServer(t)
+	newServerUnnamed(t)
+}
+
+func BenchmarkNewServer(b *testing.B) {
+	newServer(b)
+}
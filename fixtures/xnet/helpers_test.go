@@ -0,0 +1,17 @@
+package xnet_test
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func example(ctx context.Context) {
+	go func() {
+		<-ctx.Done()
+	}()
+}
+
+func TestStillUsed(t *testing.T) {
+	example(context.Background()) // fix: example(t.Context())
+}
@@ -0,0 +1,9 @@
+package xnet_test
+
+import "golang.org/x/net/context"
+
+import "testing"
+
+func TestSingle(t *testing.T) {
+	example(context.TODO()) // fix: example(t.Context())
+}
@@ -0,0 +1,16 @@
+package xnet_test
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func TestXNet(t *testing.T) {
+	ctx := context.Background() // fix: ctx := t.Context()
+	example(ctx)
+
+	t.Run("sub", func(t2 *testing.T) {
+		example(context.TODO()) // fix: example(t2.Context())
+	})
+}
@@ -0,0 +1,97 @@
+package testctxlint
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// stringListFlag is a flag.Value holding a comma-separated list of strings.
+// Passing the flag multiple times appends to the list.
+type stringListFlag []string
+
+func (f *stringListFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringListFlag) Set(value string) error {
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*f = append(*f, item)
+		}
+	}
+
+	return nil
+}
+
+// forbiddenFuncsFlag is a flag.Value mapping fully qualified names of
+// functions to replacement templates, which may be empty. Each time the flag
+// is passed, it adds an entry of the form "name" or "name=template".
+type forbiddenFuncsFlag map[string]string
+
+func (f *forbiddenFuncsFlag) String() string {
+	entries := make([]string, 0, len(*f))
+
+	for name, template := range *f {
+		if template != "" {
+			name += "=" + template
+		}
+
+		entries = append(entries, name)
+	}
+
+	sort.Strings(entries)
+
+	return strings.Join(entries, " ")
+}
+
+func (f *forbiddenFuncsFlag) Set(value string) error {
+	name, template, _ := strings.Cut(value, "=")
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.New("missing function name")
+	}
+
+	if *f == nil {
+		*f = make(forbiddenFuncsFlag)
+	}
+
+	(*f)[name] = strings.TrimSpace(template)
+
+	return nil
+}
+
+// fixStrategyFlag is a flag.Value selecting how fixes refer to the test
+// context.
+type fixStrategyFlag string
+
+const (
+	// fixInline replaces each root context with an expression obtaining the
+	// test context, like t.Context().
+	fixInline fixStrategyFlag = "inline"
+
+	// fixHoist replaces each root context with a variable holding the test
+	// context, which is declared once at the start of the test.
+	fixHoist fixStrategyFlag = "hoist"
+)
+
+func (f *fixStrategyFlag) String() string {
+	if *f == "" {
+		return string(fixInline)
+	}
+
+	return string(*f)
+}
+
+func (f *fixStrategyFlag) Set(value string) error {
+	switch strategy := fixStrategyFlag(strings.TrimSpace(value)); strategy {
+	case fixInline, fixHoist:
+		*f = strategy
+
+		return nil
+	}
+
+	return fmt.Errorf("unknown fix strategy %q, expected %s or %s", value, fixInline, fixHoist)
+}
@@ -0,0 +1,161 @@
+package testctxlint
+
+import (
+	"go/ast"
+	"go/types"
+	"slices"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+// GinkgoProvider is a [ScopeProvider] for Ginkgo specs and the asynchronous
+// assertions of Gomega.
+//
+// Spec bodies, setup and teardown nodes and table functions start a test
+// scope. Within them, an interruptible node's SpecContext parameter is used
+// as the context, otherwise GinkgoT().Context(). Functions polled by
+// Eventually or Consistently use their own context parameter, if any.
+//
+// The analyzer returned by [NewAnalyzer] includes a GinkgoProvider for the
+// official Ginkgo and Gomega packages.
+type GinkgoProvider struct {
+	// Import paths of the packages providing Ginkgo's DSL. Defaults to
+	// github.com/onsi/ginkgo/v2 and its dsl packages.
+	GinkgoPackages []string
+
+	// Import paths of the packages providing Gomega's DSL. Defaults to
+	// github.com/onsi/gomega.
+	GomegaPackages []string
+}
+
+var (
+	defaultGinkgoPackages = []string{
+		"github.com/onsi/ginkgo/v2",
+		"github.com/onsi/ginkgo/v2/dsl/core",
+		"github.com/onsi/ginkgo/v2/dsl/decorators",
+		"github.com/onsi/ginkgo/v2/dsl/table",
+	}
+	defaultGomegaPackages = []string{
+		"github.com/onsi/gomega",
+	}
+)
+
+// Ginkgo DSL functions whose function arguments run as part of a spec
+var ginkgoSpecFuncs = []string{
+	"It", "FIt", "PIt", "XIt",
+	"Specify", "FSpecify", "PSpecify", "XSpecify",
+	"BeforeEach", "AfterEach",
+	"JustBeforeEach", "JustAfterEach",
+	"BeforeAll", "AfterAll",
+	"DescribeTable", "FDescribeTable", "PDescribeTable", "XDescribeTable",
+}
+
+// Gomega DSL functions which poll their function argument
+var gomegaPollFuncs = []string{
+	"Eventually", "EventuallyWithOffset",
+	"Consistently", "ConsistentlyWithOffset",
+}
+
+func (p GinkgoProvider) FuncScope(pass *analysis.Pass, fn ast.Node, call *ast.CallExpr) *ContextSource {
+	lit, ok := fn.(*ast.FuncLit)
+	if !ok || call == nil {
+		return nil
+	}
+
+	callee, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if !ok || callee.Pkg() == nil {
+		return nil
+	}
+
+	path := callee.Pkg().Path()
+
+	switch {
+	case slices.Contains(p.ginkgoPackages(), path) && slices.Contains(ginkgoSpecFuncs, callee.Name()):
+		if tp := p.specContextParam(pass.TypesInfo, lit.Type); tp != nil {
+			return &ContextSource{Expr: tp.expr(), param: tp}
+		}
+
+		return &ContextSource{Expr: qualifier(pass.TypesInfo, call) + "GinkgoT().Context()"}
+
+	case slices.Contains(p.gomegaPackages(), path) && slices.Contains(gomegaPollFuncs, callee.Name()):
+		if tp := p.specContextParam(pass.TypesInfo, lit.Type); tp != nil {
+			return &ContextSource{Expr: tp.expr(), param: tp}
+		}
+	}
+
+	return nil
+}
+
+func (p GinkgoProvider) ginkgoPackages() []string {
+	if p.GinkgoPackages == nil {
+		return defaultGinkgoPackages
+	}
+
+	return p.GinkgoPackages
+}
+
+func (p GinkgoProvider) gomegaPackages() []string {
+	if p.GomegaPackages == nil {
+		return defaultGomegaPackages
+	}
+
+	return p.GomegaPackages
+}
+
+// specContextParam returns the first parameter of fnType if it is a
+// context.Context or Ginkgo's SpecContext.
+func (p GinkgoProvider) specContextParam(info *types.Info, fnType *ast.FuncType) *testingParam {
+	if len(fnType.Params.List) == 0 {
+		return nil
+	}
+
+	param := fnType.Params.List[0]
+
+	typ := info.TypeOf(param.Type)
+	if typ == nil || (!isContextType(typ) && !p.isSpecContextType(typ)) {
+		return nil
+	}
+
+	return newTestingParam(info, fnType, param, "ctx")
+}
+
+// isSpecContextType reports whether typ is Ginkgo's SpecContext, which is an
+// alias of a type in Ginkgo's internal package.
+func (p GinkgoProvider) isSpecContextType(typ types.Type) bool {
+	named, ok := types.Unalias(typ).(*types.Named)
+	if !ok || named.Obj().Name() != "SpecContext" || named.Obj().Pkg() == nil {
+		return false
+	}
+
+	path := named.Obj().Pkg().Path()
+
+	for _, pkg := range p.ginkgoPackages() {
+		if path == pkg || path == pkg+"/internal" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// qualifier returns the package qualifier, including the trailing dot, with
+// which call refers to its callee. It returns an empty string for callees
+// which are not qualified, such as those of dot-imported packages.
+func qualifier(info *types.Info, call *ast.CallExpr) string {
+	sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok {
+		return ""
+	}
+
+	id, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return ""
+	}
+
+	if _, ok := info.Uses[id].(*types.PkgName); !ok {
+		return ""
+	}
+
+	return id.Name + "."
+}
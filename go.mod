@@ -6,6 +6,7 @@ require (
 	github.com/josephspurrier/goversioninfo v1.5.0
 	github.com/stretchr/testify v1.10.0
 	golang.org/x/mod v0.26.0
+	golang.org/x/net v0.42.0
 	golang.org/x/tools v0.35.0
 )
 
@@ -0,0 +1,66 @@
+package testctxlint
+
+import (
+	"go/ast"
+	"go/token"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// hoistedSource returns a source referring to a variable which holds the
+// context of source and is declared at the start of the test, as in
+//
+//	func TestX(t *testing.T) {
+//		ctx := t.Context()
+//		...
+//	}
+//
+// The variable is named ctx unless that name is taken. It returns source
+// itself if source is not the context of the test, like a context parameter
+// in scope, if it is a variable already, or if the body of the test does not
+// start on a line of its own.
+func (c *scopeChecker) hoistedSource(source *ContextSource) *ContextSource {
+	test := c.s.sourceScope()
+	if test == nil || source != test.source || token.IsIdentifier(source.Expr) {
+		return source
+	}
+
+	var (
+		fnType *ast.FuncType
+		body   *ast.BlockStmt
+	)
+
+	switch fn := test.Node.(type) {
+	case *ast.FuncDecl:
+		fnType, body = fn.Type, fn.Body
+	case *ast.FuncLit:
+		fnType, body = fn.Type, fn.Body
+	}
+
+	if body == nil || len(body.List) == 0 {
+		return source
+	}
+
+	fset := c.r.pass.Fset
+
+	first := fset.Position(body.List[0].Pos())
+	if fset.Position(body.Lbrace).Line == first.Line {
+		return source
+	}
+
+	name := freeName(c.r.pass.TypesInfo, fnType, "ctx")
+	start := fset.File(body.Lbrace).LineStart(first.Line)
+
+	hoisted := *source
+	hoisted.Expr = name
+	hoisted.shared = append(slices.Clone(source.shared), analysis.TextEdit{
+		Pos: start,
+		End: start,
+		// Indent like the first statement, which is indented with tabs
+		NewText: []byte(strings.Repeat("\t", first.Column-1) + name + " := " + source.Expr + "\n\n"),
+	})
+
+	return &hoisted
+}
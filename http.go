@@ -0,0 +1,56 @@
+package testctxlint
+
+import (
+	"go/ast"
+	"go/types"
+)
+
+// handlerReason explains why the request's context is used within HTTP
+// handlers instead of the test context.
+const handlerReason = "HTTP handlers should use the context of the request they serve, " +
+	"which is canceled once the request is done, rather than the test context"
+
+// handlerSource returns the source of the request context for the HTTP
+// handler lit, such as the function passed to http.HandlerFunc in
+//
+//	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+//		...
+//	}))
+//
+// It returns nil if lit does not take an *http.Request or is not defined
+// within a test scope of scopeCol.
+func handlerSource(info *types.Info, scopeCol *scopeCollection, lit *ast.FuncLit) *ContextSource {
+	if parent := scopeCol.findScope(lit.Pos()); parent == nil || parent.findNearestContextSource() == nil {
+		return nil
+	}
+
+	for _, param := range lit.Type.Params.List {
+		if !isHTTPRequestPointer(info.TypeOf(param.Type)) {
+			continue
+		}
+
+		tp := newTestingParam(info, lit.Type, param, "r")
+		tp.reason = handlerReason
+
+		return tp.contextSource()
+	}
+
+	return nil
+}
+
+// isHTTPRequestPointer reports whether typ is *net/http.Request.
+func isHTTPRequestPointer(typ types.Type) bool {
+	p, ok := types.Unalias(typ).(*types.Pointer)
+	if !ok {
+		return false
+	}
+
+	named, ok := types.Unalias(p.Elem()).(*types.Named)
+	if !ok {
+		return false
+	}
+
+	obj := named.Obj()
+
+	return obj.Pkg() != nil && obj.Pkg().Path() == "net/http" && obj.Name() == "Request"
+}
@@ -0,0 +1,206 @@
+package testctxlint
+
+import (
+	"bytes"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// removeUnusedImports adds the removal of imports which are no longer used
+// once all fixes for file have been applied to the fixes of its diagnostics.
+// As each fix removes them, they are removed no matter which fixes are
+// applied last, and merging identical edits removes them only once.
+func (r *reporter) removeUnusedImports(file *ast.File) {
+	var (
+		fixed   []int
+		removed []ast.Node
+		edits   []analysis.TextEdit
+	)
+
+	for i, diag := range r.diagnostics {
+		if diag.Pos < file.FileStart || diag.Pos > file.FileEnd || len(diag.SuggestedFixes) == 0 {
+			continue
+		}
+
+		fixed = append(fixed, i)
+		removed = append(removed, r.removed[i]...)
+		edits = append(edits, diag.SuggestedFixes[0].TextEdits...)
+	}
+
+	if len(fixed) == 0 {
+		return
+	}
+
+	for _, spec := range file.Imports {
+		path, ok := importPath(spec)
+		if !ok || !slices.Contains(r.removableImports, path) {
+			continue
+		}
+
+		pkgName := r.pass.TypesInfo.PkgNameOf(spec)
+		if pkgName == nil || pkgName.Name() == "." || pkgName.Name() == "_" {
+			continue
+		}
+
+		// Replacements may refer to the package as well
+		if slices.ContainsFunc(edits, func(edit analysis.TextEdit) bool {
+			return bytes.Contains(edit.NewText, []byte(pkgName.Name()+"."))
+		}) {
+			continue
+		}
+
+		if !isUnusedAfterRemoval(r.pass.TypesInfo, file, pkgName, removed) {
+			continue
+		}
+
+		edit := deleteImportEdit(r.pass.Fset, file, spec)
+
+		for _, i := range fixed {
+			// Fixes may share their edits with other diagnostics
+			fix := &r.diagnostics[i].SuggestedFixes[0]
+			fix.Message += " and remove unused import " + spec.Path.Value
+			fix.TextEdits = append(slices.Clip(fix.TextEdits), edit)
+		}
+	}
+}
+
+// importPath returns the unquoted import path of spec.
+func importPath(spec *ast.ImportSpec) (string, bool) {
+	path := spec.Path.Value
+	if len(path) < 2 {
+		return "", false
+	}
+
+	return path[1 : len(path)-1], true
+}
+
+// isUnusedAfterRemoval reports whether all uses of pkgName in file are within
+// the removed nodes.
+func isUnusedAfterRemoval(info *types.Info, file *ast.File, pkgName *types.PkgName, removed []ast.Node) bool {
+	for id, obj := range info.Uses {
+		if obj != pkgName || id.Pos() < file.FileStart || id.Pos() > file.FileEnd {
+			continue
+		}
+
+		if !slices.ContainsFunc(removed, func(n ast.Node) bool {
+			return n.Pos() <= id.Pos() && id.End() <= n.End()
+		}) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// deleteImportEdit returns an edit deleting the lines of spec, or of the
+// whole import declaration if spec is its only import.
+func deleteImportEdit(fset *token.FileSet, file *ast.File, spec *ast.ImportSpec) analysis.TextEdit {
+	return deleteSpecEdit(fset, file, spec, spec.Doc)
+}
+
+// deleteSpecEdit returns an edit deleting the lines of spec along with its
+// documentation doc, or of the whole declaration if spec is its only spec.
+func deleteSpecEdit(fset *token.FileSet, file *ast.File, spec ast.Spec, doc *ast.CommentGroup) analysis.TextEdit {
+	var node ast.Node = spec
+
+	for _, decl := range file.Decls {
+		if decl, ok := decl.(*ast.GenDecl); ok && slices.Contains(decl.Specs, spec) {
+			if len(decl.Specs) == 1 {
+				node = decl
+				doc = decl.Doc
+			}
+
+			break
+		}
+	}
+
+	start := node.Pos()
+	if doc != nil {
+		start = doc.Pos()
+	}
+
+	return deleteLinesEdit(fset, start, node.End())
+}
+
+// deleteLinesEdit returns an edit deleting the lines from the one of start
+// to the one of end, including the line break.
+func deleteLinesEdit(fset *token.FileSet, start, end token.Pos) analysis.TextEdit {
+	tokFile := fset.File(start)
+	startLine := tokFile.Line(start)
+	endLine := tokFile.Line(end)
+
+	edit := analysis.TextEdit{
+		Pos: tokFile.LineStart(startLine),
+		End: tokFile.Pos(tokFile.Size()),
+	}
+
+	if endLine < tokFile.LineCount() {
+		edit.End = tokFile.LineStart(endLine + 1)
+	}
+
+	return edit
+}
+
+// importedName returns the name under which file refers to the package with
+// the given import path, along with the edits importing it if necessary. It
+// reports false if the package is not imported and its name is taken.
+func importedName(pass *analysis.Pass, file *ast.File, path string) (string, []analysis.TextEdit, bool) {
+	for _, spec := range file.Imports {
+		if p, ok := importPath(spec); !ok || p != path {
+			continue
+		}
+
+		if pkgName := pass.TypesInfo.PkgNameOf(spec); pkgName != nil &&
+			pkgName.Name() != "." && pkgName.Name() != "_" {
+			return pkgName.Name(), nil, true
+		}
+	}
+
+	name := path[strings.LastIndex(path, "/")+1:]
+
+	// The name must not be taken by another import or declaration
+	if scope := pass.TypesInfo.Scopes[file]; scope != nil && scope.Lookup(name) != nil ||
+		pass.Pkg.Scope().Lookup(name) != nil {
+		return "", nil, false
+	}
+
+	return name, []analysis.TextEdit{importEdit(file, path)}, true
+}
+
+// importEdit returns an edit adding an import of path to file.
+func importEdit(file *ast.File, path string) analysis.TextEdit {
+	quoted := strconv.Quote(path)
+
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+
+		if gen.Lparen.IsValid() && len(gen.Specs) > 0 {
+			return analysis.TextEdit{
+				Pos:     gen.Specs[0].Pos(),
+				End:     gen.Specs[0].Pos(),
+				NewText: []byte(quoted + "\n\t"),
+			}
+		}
+
+		return analysis.TextEdit{
+			Pos:     gen.Pos(),
+			End:     gen.Pos(),
+			NewText: []byte("import " + quoted + "\n\n"),
+		}
+	}
+
+	return analysis.TextEdit{
+		Pos:     file.Name.End(),
+		End:     file.Name.End(),
+		NewText: []byte("\n\nimport " + quoted),
+	}
+}
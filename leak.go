@@ -0,0 +1,202 @@
+package testctxlint
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// subtestReason explains why subtests must not use the contexts of their
+// parent tests.
+const subtestReason = "the parent's context is only canceled once the parent test has finished, " +
+	"unlike the subtest's own context"
+
+// testContextVars returns the variables of the package holding a test
+// context, or a root context which is about to be replaced by one, like ctx
+// in
+//
+//	ctx := t.Context()
+//
+// along with their uses.
+func testContextVars(pass *analysis.Pass, isForbidden func(*types.Func) bool) map[*types.Var][]*ast.Ident {
+	vars := map[*types.Var][]*ast.Ident{}
+
+	add := func(name *ast.Ident, value ast.Expr) {
+		v, ok := pass.TypesInfo.Defs[name].(*types.Var)
+		if !ok {
+			return
+		}
+
+		call, ok := ast.Unparen(value).(*ast.CallExpr)
+		if !ok {
+			return
+		}
+
+		if x, _, _ := forbiddenMethod(pass.TypesInfo, call, isForbidden); x != nil ||
+			isTestingContextCall(pass.TypesInfo, call) {
+			vars[v] = nil
+		}
+	}
+
+	for _, file := range pass.Files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.AssignStmt:
+				if n.Tok != token.DEFINE || len(n.Lhs) != len(n.Rhs) {
+					break
+				}
+
+				for i, lhs := range n.Lhs {
+					if id, ok := lhs.(*ast.Ident); ok {
+						add(id, n.Rhs[i])
+					}
+				}
+
+			case *ast.ValueSpec:
+				if len(n.Names) != len(n.Values) {
+					break
+				}
+
+				for i, name := range n.Names {
+					add(name, n.Values[i])
+				}
+			}
+
+			return true
+		})
+	}
+
+	for id, obj := range pass.TypesInfo.Uses {
+		if v, ok := obj.(*types.Var); ok {
+			if uses, ok := vars[v]; ok {
+				vars[v] = append(uses, id)
+			}
+		}
+	}
+
+	return vars
+}
+
+// checkParentContextCall reports calls obtaining the context of a parent
+// test from within a subtest, such as t.Context() in
+//
+//	t.Run("sub", func(t2 *testing.T) {
+//		doSomething(t.Context())
+//	})
+func (c *scopeChecker) checkParentContextCall(call *ast.CallExpr) {
+	info := c.r.pass.TypesInfo
+
+	if len(c.parents) == 0 || !isTestingContextCall(info, call) {
+		return
+	}
+
+	sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok {
+		return
+	}
+
+	id, ok := ast.Unparen(sel.X).(*ast.Ident)
+	if !ok {
+		return
+	}
+
+	obj := info.Uses[id]
+	if obj == nil {
+		return
+	}
+
+	for _, p := range c.parents {
+		if p.source.param == nil || p.source.param.object(info) != obj {
+			continue
+		}
+
+		replaced := types.ExprString(call)
+		c.reportParentContext(call, "call to "+replaced+" from a subtest of "+id.Name, replaced)
+
+		return
+	}
+}
+
+// checkParentContextVar reports uses of variables holding the context of a
+// parent test from within a subtest, such as ctx in
+//
+//	ctx := t.Context()
+//	t.Run("sub", func(t2 *testing.T) {
+//		doSomething(ctx)
+//	})
+func (c *scopeChecker) checkParentContextVar(id *ast.Ident) {
+	if len(c.parents) == 0 || c.assigned[id] {
+		return
+	}
+
+	v, ok := c.r.pass.TypesInfo.Uses[id].(*types.Var)
+	if _, isCtxVar := c.ctxVars[v]; !ok || !isCtxVar {
+		return
+	}
+
+	// Variables declared within the subtest are fine, as are those the
+	// subtest assigns a context of its own
+	test := c.s.sourceScope()
+	if test.Pos() <= v.Pos() && v.Pos() < test.End() || isAssignedWithin(c.r.pass.TypesInfo, test.Node, v) {
+		return
+	}
+
+	for _, p := range c.parents {
+		if p.Pos() <= v.Pos() && v.Pos() < p.End() {
+			message := "use of " + id.Name + " of the parent test from a subtest"
+
+			// Replacing the last uses of v would leave it unused, which does
+			// not compile
+			if !c.usedBy(v, p) {
+				c.r.report(analysis.Diagnostic{Pos: id.Pos(), End: id.End(), Message: message + " (" + subtestReason + ")"})
+
+				return
+			}
+
+			c.reportParentContext(id, message, id.Name)
+
+			return
+		}
+	}
+}
+
+// usedBy reports whether v is used by the test of scope s itself, rather
+// than only by its subtests.
+func (c *scopeChecker) usedBy(v *types.Var, s *scope) bool {
+	for _, id := range c.ctxVars[v] {
+		if use := c.scopeCol.findScope(id.Pos()); use != nil && use.sourceScope() == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isAssignedWithin reports whether v is assigned to within node.
+func isAssignedWithin(info *types.Info, node ast.Node, v *types.Var) bool {
+	var assigned bool
+
+	ast.Inspect(node, func(n ast.Node) bool {
+		if assign, ok := n.(*ast.AssignStmt); ok && assign.Tok == token.ASSIGN {
+			for _, lhs := range assign.Lhs {
+				if id, ok := ast.Unparen(lhs).(*ast.Ident); ok && info.Uses[id] == v {
+					assigned = true
+				}
+			}
+		}
+
+		return !assigned
+	})
+
+	return assigned
+}
+
+// reportParentContext reports node, described by replaced, which refers to
+// the context of a parent test and suggests the subtest's context instead.
+func (c *scopeChecker) reportParentContext(node ast.Node, message, replaced string) {
+	source := c.s.findNearestContextSource()
+
+	reportReplacement(c.r, node, message+" ("+subtestReason+")", replaced, source, source.Expr)
+}
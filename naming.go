@@ -0,0 +1,144 @@
+package testctxlint
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+	"strconv"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// newTestingParam returns param of the function with type fnType as a testing
+// handle. Unnamed and blank parameters are given a synthetic name based on
+// base, see freeName.
+func newTestingParam(info *types.Info, fnType *ast.FuncType, param *ast.Field, base string) *testingParam {
+	if len(param.Names) > 0 && param.Names[0].Name != "_" {
+		return &testingParam{
+			ident:     param.Names[0],
+			isUnnamed: false,
+			param:     param,
+		}
+	}
+
+	return &testingParam{
+		ident: &ast.Ident{
+			Name: freeName(info, fnType, base),
+			// Use the position of the type for the synthetic identifier
+			NamePos: param.Type.Pos(),
+		},
+		isUnnamed: true,
+		param:     param,
+		fnType:    fnType,
+	}
+}
+
+// freeName returns base, or base followed by a number, such that the name
+// neither refers to anything within the function with type fnType nor is
+// declared within it. Otherwise, naming a parameter of the function that way
+// would change what existing code refers to, or uses of the parameter would
+// refer to something else.
+//
+// Declarations of testing handles by nested functions, like t in
+//
+//	func TestX(*testing.T) {
+//		t.Run("sub", func(t *testing.T) { ... })
+//	}
+//
+// are fine, as the nested functions use their own handles anyway.
+func freeName(info *types.Info, fnType *ast.FuncType, base string) string {
+	scope := info.Scopes[fnType]
+
+	for i := 1; ; i++ {
+		name := base
+		if i > 1 {
+			name += strconv.Itoa(i)
+		}
+
+		if scope == nil {
+			return name
+		}
+
+		if _, obj := scope.LookupParent(name, token.NoPos); obj == nil && !declaredWithin(info, scope, name) {
+			return name
+		}
+	}
+}
+
+// declaredWithin reports whether name is declared within a scope nested in
+// scope, other than as a testing handle parameter of a nested function.
+func declaredWithin(info *types.Info, scope *types.Scope, name string) bool {
+	for i := range scope.NumChildren() {
+		child := scope.Child(i)
+
+		if obj := child.Lookup(name); obj != nil {
+			if !isTestingParamOf(info, child, obj) {
+				return true
+			}
+
+			continue // the nested function uses its own handle
+		}
+
+		if declaredWithin(info, child, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isTestingParamOf reports whether obj is a testing handle parameter of the
+// function with the given scope.
+func isTestingParamOf(info *types.Info, scope *types.Scope, obj types.Object) bool {
+	if _, ok := testingTypeName(obj.Type()); !ok {
+		return false
+	}
+
+	for node, s := range info.Scopes {
+		if s == scope {
+			fnType, ok := node.(*ast.FuncType)
+
+			return ok && obj.Pos() < fnType.End()
+		}
+	}
+
+	return false
+}
+
+// nameEdits returns the edits naming the unnamed or blank parameter or
+// receiver of tp. As parameters must either all be named or all be unnamed,
+// other unnamed parameters of the function are named _.
+func (tp *testingParam) nameEdits() []analysis.TextEdit {
+	if len(tp.param.Names) > 0 {
+		// Rename the blank identifier
+		return []analysis.TextEdit{{
+			Pos:     tp.param.Names[0].Pos(),
+			End:     tp.param.Names[0].End(),
+			NewText: []byte(tp.ident.Name),
+		}}
+	}
+
+	edits := []analysis.TextEdit{{
+		// Add parameter name before the type
+		Pos:     tp.param.Type.Pos(),
+		End:     tp.param.Type.Pos(),
+		NewText: []byte(tp.ident.Name + " "),
+	}}
+
+	if tp.fnType == nil || !slices.Contains(tp.fnType.Params.List, tp.param) {
+		return edits
+	}
+
+	for _, field := range tp.fnType.Params.List {
+		if field != tp.param && len(field.Names) == 0 {
+			edits = append(edits, analysis.TextEdit{
+				Pos:     field.Type.Pos(),
+				End:     field.Type.Pos(),
+				NewText: []byte("_ "),
+			})
+		}
+	}
+
+	return edits
+}
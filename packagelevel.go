@@ -0,0 +1,448 @@
+package testctxlint
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// packageLevelChecker checks root contexts created by the package-level
+// variables of test files, like
+//
+//	var ctx = context.Background()
+//
+//	var tests = []struct {
+//		name string
+//		ctx  context.Context
+//	}{
+//		{name: "a", ctx: context.Background()},
+//	}
+type packageLevelChecker struct {
+	*analyzer
+
+	r        *reporter
+	scopeCol *scopeCollection
+
+	// Identifiers referring to each object
+	uses map[types.Object][]*ast.Ident
+
+	// Selector expressions selecting each field
+	selectors map[*types.Var][]*ast.SelectorExpr
+
+	// Objects which are assigned to or whose address is taken
+	modified map[types.Object]bool
+
+	// Struct types used in composite literals without keys
+	unkeyed []*types.Struct
+}
+
+func (a *analyzer) checkPackageLevel(r *reporter, scopeCol *scopeCollection) {
+	c := &packageLevelChecker{
+		analyzer:  a,
+		r:         r,
+		scopeCol:  scopeCol,
+		uses:      map[types.Object][]*ast.Ident{},
+		selectors: map[*types.Var][]*ast.SelectorExpr{},
+		modified:  map[types.Object]bool{},
+	}
+
+	for _, file := range r.pass.Files {
+		if !strings.HasSuffix(r.pass.Fset.File(file.Pos()).Name(), "_test.go") {
+			continue
+		}
+
+		for _, decl := range file.Decls {
+			if decl, ok := decl.(*ast.GenDecl); ok && decl.Tok == token.VAR {
+				for _, spec := range decl.Specs {
+					c.checkSpec(file, spec.(*ast.ValueSpec))
+				}
+			}
+		}
+	}
+}
+
+// index records the uses of objects in the package. It is only done once
+// a package-level root context has been found.
+func (c *packageLevelChecker) index() {
+	if len(c.uses) > 0 {
+		return
+	}
+
+	info := c.r.pass.TypesInfo
+
+	for id, obj := range info.Uses {
+		c.uses[obj] = append(c.uses[obj], id)
+	}
+
+	modify := func(expr ast.Expr) {
+		switch expr := ast.Unparen(expr).(type) {
+		case *ast.Ident:
+			c.modified[info.Uses[expr]] = true
+		case *ast.SelectorExpr:
+			c.modified[info.Uses[expr.Sel]] = true
+		}
+	}
+
+	for _, file := range c.r.pass.Files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.AssignStmt:
+				if n.Tok != token.DEFINE {
+					for _, lhs := range n.Lhs {
+						modify(lhs)
+					}
+				}
+
+			case *ast.IncDecStmt:
+				modify(n.X)
+
+			case *ast.UnaryExpr:
+				if n.Op == token.AND {
+					modify(n.X)
+				}
+
+			case *ast.SelectorExpr:
+				if sel := info.Selections[n]; sel != nil && sel.Kind() == types.FieldVal {
+					field := sel.Obj().(*types.Var)
+					c.selectors[field] = append(c.selectors[field], n)
+				}
+
+			case *ast.CompositeLit:
+				st, ok := info.TypeOf(n).Underlying().(*types.Struct)
+				if ok && len(n.Elts) > 0 {
+					if _, ok := n.Elts[0].(*ast.KeyValueExpr); !ok {
+						c.unkeyed = append(c.unkeyed, st)
+					}
+				}
+			}
+
+			return true
+		})
+	}
+}
+
+// checkSpec checks the values of a package-level variable declaration.
+func (c *packageLevelChecker) checkSpec(file *ast.File, spec *ast.ValueSpec) {
+	info := c.r.pass.TypesInfo
+
+	for i, value := range spec.Values {
+		names := spec.Names
+		if len(spec.Names) == len(spec.Values) {
+			names = names[i : i+1]
+		}
+
+		// Keys of the struct fields calls are the values of
+		keys := map[*ast.CallExpr]*ast.Ident{}
+
+		ast.Inspect(value, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.FuncLit:
+				return false // either checked as a scope or not run by a test
+
+			case *ast.KeyValueExpr:
+				key, ok := n.Key.(*ast.Ident)
+				call, isCall := ast.Unparen(n.Value).(*ast.CallExpr)
+
+				if _, isField := info.Uses[key].(*types.Var); ok && isCall && isField {
+					keys[call] = key
+				}
+
+			case *ast.CallExpr:
+				c.checkCall(file, spec, names, n, keys[n])
+			}
+
+			return true
+		})
+	}
+}
+
+// checkCall reports call if it creates a root context within the value of
+// the package-level variables names, directly or as the value of the struct
+// field with key field.
+func (c *packageLevelChecker) checkCall(
+	file *ast.File, spec *ast.ValueSpec, names []*ast.Ident, call *ast.CallExpr, field *ast.Ident,
+) {
+	x, sel, fn := forbiddenMethod(c.r.pass.TypesInfo, call, c.isForbidden)
+	if x == nil {
+		return
+	}
+
+	c.index()
+
+	var (
+		fixMessage string
+		edits      []analysis.TextEdit
+	)
+
+	switch {
+	case field == nil && len(spec.Names) == 1 && len(spec.Values) == 1 && ast.Unparen(spec.Values[0]) == call:
+		fixMessage, edits = c.varFix(file, spec, names[0])
+	case field != nil:
+		fixMessage, edits = c.fieldFix(field)
+	}
+
+	diag := analysis.Diagnostic{
+		Pos: call.Pos(),
+		End: call.End(),
+		Message: fmt.Sprintf("call to %s in package-level variable %s of a test file",
+			formatMethod(sel, fn), identList(names)),
+	}
+
+	if consumers := c.consumers(names); len(consumers) > 0 {
+		diag.Message += ", used by " + strings.Join(consumers, ", ")
+	}
+
+	if edits != nil {
+		diag.SuggestedFixes = []analysis.SuggestedFix{{Message: fixMessage, TextEdits: edits}}
+	}
+
+	c.r.removable(fn.Pkg().Path())
+	c.r.report(diag, call)
+}
+
+// consumers returns the names of the functions using any of the variables
+// names, in order of their declaration.
+func (c *packageLevelChecker) consumers(names []*ast.Ident) []string {
+	var consumers []string
+
+	for _, file := range c.r.pass.Files {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok {
+				continue
+			}
+
+			for _, name := range names {
+				if c.usedWithin(c.r.pass.TypesInfo.Defs[name], fn) {
+					consumers = append(consumers, fn.Name.Name)
+
+					break
+				}
+			}
+		}
+	}
+
+	return consumers
+}
+
+// usedWithin reports whether obj is used within node.
+func (c *packageLevelChecker) usedWithin(obj types.Object, node ast.Node) bool {
+	for _, id := range c.uses[obj] {
+		if node.Pos() <= id.Pos() && id.End() <= node.End() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// useSource returns the source of the test context available at a use of a
+// package-level context at pos, or nil if there is none which can be used.
+func (c *packageLevelChecker) useSource(pos token.Pos) *ContextSource {
+	s := c.scopeCol.findScope(pos)
+	if s == nil || s.inCleanup() {
+		return nil
+	}
+
+	source := s.contextSourceAt(c.r.pass.TypesInfo, c.r.pass.Pkg, pos)
+	if source == nil || source.param != nil && source.param.isUnnamed {
+		return nil
+	}
+
+	return source
+}
+
+// varFix returns a fix removing the package-level context variable name and
+// replacing its uses with the contexts of the consuming tests, or nil edits
+// if that is not possible.
+func (c *packageLevelChecker) varFix(
+	file *ast.File, spec *ast.ValueSpec, name *ast.Ident,
+) (string, []analysis.TextEdit) {
+	obj := c.r.pass.TypesInfo.Defs[name]
+	if obj == nil || c.modified[obj] || !isContextType(obj.Type()) {
+		return "", nil
+	}
+
+	edits := []analysis.TextEdit{deleteSpecEdit(c.r.pass.Fset, file, spec, spec.Doc)}
+
+	for _, id := range c.uses[obj] {
+		source := c.useSource(id.Pos())
+		if source == nil {
+			return "", nil
+		}
+
+		edits = append(edits, analysis.TextEdit{Pos: id.Pos(), End: id.End(), NewText: []byte(source.Expr)})
+		edits = append(edits, source.Edits...)
+	}
+
+	return "remove " + name.Name + " and use the test context instead", edits
+}
+
+// fieldFix returns a fix turning the context field key of a test table into
+// a function taking the test, or nil edits if that is not possible.
+func (c *packageLevelChecker) fieldFix(key *ast.Ident) (string, []analysis.TextEdit) {
+	info := c.r.pass.TypesInfo
+
+	field, ok := info.Uses[key].(*types.Var)
+	if !ok || c.modified[field] || !isContextType(field.Type()) {
+		return "", nil
+	}
+
+	decl, declFile := c.fieldDecl(field)
+	if decl == nil || len(decl.Names) != 1 {
+		return "", nil
+	}
+
+	for _, st := range c.unkeyed {
+		for f := range st.Fields() {
+			if f == field {
+				return "", nil
+			}
+		}
+	}
+
+	fnType, ok := c.testFuncType(declFile, field.Type(), "")
+	if !ok {
+		return "", nil
+	}
+
+	edits := []analysis.TextEdit{{Pos: decl.Type.Pos(), End: decl.Type.End(), NewText: []byte(fnType)}}
+
+	// All values of the field must be root contexts, as they are replaced
+	// by functions returning the test context.
+	for _, id := range c.uses[field] {
+		if slices.ContainsFunc(c.selectors[field], func(sel *ast.SelectorExpr) bool { return sel.Sel == id }) {
+			continue
+		}
+
+		value := c.keyedValue(id)
+		if value == nil {
+			return "", nil
+		}
+
+		if x, _, _ := forbiddenMethod(info, value, c.isForbidden); x == nil {
+			return "", nil
+		}
+
+		fnLit, ok := c.testFuncType(fileOf(c.r.pass, value.Pos()), field.Type(), "tb ")
+		if !ok {
+			return "", nil
+		}
+
+		edits = append(edits, analysis.TextEdit{
+			Pos:     value.Pos(),
+			End:     value.End(),
+			NewText: []byte(fnLit + " { return tb.Context() }"),
+		})
+	}
+
+	for _, sel := range c.selectors[field] {
+		source := c.useSource(sel.Pos())
+		if source == nil || source.param == nil {
+			return "", nil
+		}
+
+		edits = append(edits, analysis.TextEdit{
+			Pos:     sel.End(),
+			End:     sel.End(),
+			NewText: []byte("(" + source.param.expr() + ")"),
+		})
+	}
+
+	return "turn field " + key.Name + " into a function taking the test", edits
+}
+
+// fieldDecl returns the declaration of field and the file containing it.
+func (c *packageLevelChecker) fieldDecl(field *types.Var) (*ast.Field, *ast.File) {
+	for _, file := range c.r.pass.Files {
+		if field.Pos() < file.FileStart || field.Pos() > file.FileEnd {
+			continue
+		}
+
+		var decl *ast.Field
+
+		ast.Inspect(file, func(n ast.Node) bool {
+			if f, ok := n.(*ast.Field); ok && slices.ContainsFunc(f.Names, func(name *ast.Ident) bool {
+				return c.r.pass.TypesInfo.Defs[name] == field
+			}) {
+				decl = f
+			}
+
+			return decl == nil
+		})
+
+		return decl, file
+	}
+
+	return nil, nil
+}
+
+// keyedValue returns the value of the composite literal element with key id
+// if it is a call.
+func (c *packageLevelChecker) keyedValue(id *ast.Ident) *ast.CallExpr {
+	for _, file := range c.r.pass.Files {
+		if id.Pos() < file.FileStart || id.Pos() > file.FileEnd {
+			continue
+		}
+
+		var value *ast.CallExpr
+
+		ast.Inspect(file, func(n ast.Node) bool {
+			if kv, ok := n.(*ast.KeyValueExpr); ok && kv.Key == id {
+				value, _ = ast.Unparen(kv.Value).(*ast.CallExpr)
+			}
+
+			return value == nil
+		})
+
+		return value
+	}
+
+	return nil
+}
+
+// testFuncType returns the type of a function taking a testing.TB, named
+// param, and returning result, as it is written in file.
+func (c *packageLevelChecker) testFuncType(file *ast.File, result types.Type, param string) (string, bool) {
+	testingName, ok := "", false
+
+	qualifier := func(pkg *types.Package) string {
+		for _, spec := range file.Imports {
+			if pkgName := c.r.pass.TypesInfo.PkgNameOf(spec); pkgName != nil && pkgName.Imported() == pkg {
+				return pkgName.Name()
+			}
+		}
+
+		ok = false
+
+		return pkg.Name()
+	}
+
+	for _, spec := range file.Imports {
+		if path, _ := importPath(spec); path == "testing" {
+			if pkgName := c.r.pass.TypesInfo.PkgNameOf(spec); pkgName != nil &&
+				pkgName.Name() != "." && pkgName.Name() != "_" {
+				testingName, ok = pkgName.Name(), true
+			}
+		}
+	}
+
+	resultType := types.TypeString(result, qualifier)
+
+	return "func(" + param + testingName + ".TB) " + resultType, ok
+}
+
+// identList formats names as a comma-separated list.
+func identList(names []*ast.Ident) string {
+	list := make([]string, len(names))
+	for i, name := range names {
+		list[i] = name.Name
+	}
+
+	return strings.Join(list, ", ")
+}
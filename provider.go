@@ -0,0 +1,74 @@
+package testctxlint
+
+import (
+	"go/ast"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// ScopeProvider teaches the analyzer about a test framework. It decides which
+// functions start a test scope and how code within that scope obtains the
+// test's context.
+//
+// Providers are consulted in order for every function declaration and
+// function literal, and the first non-nil [ContextSource] returned is used.
+// The built-in providers for the testing package, testify suites and Ginkgo
+// always come first.
+type ScopeProvider interface {
+	// FuncScope returns the context source of the test scope started by fn,
+	// which is either an *ast.FuncDecl or an *ast.FuncLit, or nil if fn does
+	// not start a test scope.
+	//
+	// If fn is a function literal passed as an argument to a call, such as
+	// t.Run("name", fn), call is that call expression. Otherwise it is nil.
+	FuncScope(pass *analysis.Pass, fn ast.Node, call *ast.CallExpr) *ContextSource
+}
+
+// ContextSource describes how code within a test scope obtains the test's
+// context.
+type ContextSource struct {
+	// Expr is the expression that evaluates to the test's context, such as
+	// "t.Context()" or "ctx". It replaces calls to context.Background and
+	// context.TODO within the scope.
+	Expr string
+
+	// Edits are applied along with the replacement, if Expr requires any
+	// further changes to be valid.
+	Edits []analysis.TextEdit
+
+	// Reason optionally explains why Expr has been chosen. It is added to
+	// the diagnostics.
+	Reason string
+
+	// The testing handle parameter Expr refers to, if any
+	param *testingParam
+
+	// Edits required by Expr which are shared by all replacements, like the
+	// declaration of a variable
+	shared []analysis.TextEdit
+}
+
+// testingScopes is the built-in provider for the testing package and
+// testify suites.
+type testingScopes struct{}
+
+func (testingScopes) FuncScope(pass *analysis.Pass, fn ast.Node, _ *ast.CallExpr) *ContextSource {
+	var tp *testingParam
+
+	switch fn := fn.(type) {
+	case *ast.FuncDecl:
+		tp = benchmarkOrTestParamWithInfo(pass.TypesInfo, fn.Type)
+		if tp == nil {
+			tp = methodTestingParam(pass.TypesInfo, fn)
+		}
+
+	case *ast.FuncLit:
+		tp = benchmarkOrTestParamWithInfo(pass.TypesInfo, fn.Type)
+	}
+
+	if tp == nil {
+		return nil
+	}
+
+	return tp.contextSource()
+}
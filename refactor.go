@@ -0,0 +1,354 @@
+package testctxlint
+
+import (
+	"go/ast"
+	"go/types"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// helperRefactorer builds fixes threading the test context through the
+// helpers of a package which create their own root contexts, like
+//
+//	func newClient() *Client {
+//		return dial(context.Background())
+//	}
+//
+// turning them into
+//
+//	func newClient(ctx context.Context) *Client {
+//		return dial(ctx)
+//	}
+//
+// and passing the test context at every call site.
+type helperRefactorer struct {
+	*analyzer
+
+	pass     *analysis.Pass
+	scopeCol *scopeCollection
+
+	// Function declarations of the package
+	decls map[*types.Func]*ast.FuncDecl
+
+	// Calls of each function of the package, in order
+	calls map[*types.Func][]*ast.CallExpr
+
+	// Functions of the package referred to by other means than calls
+	referenced map[*types.Func]bool
+
+	// Fixes found so far; nil for helpers which cannot be refactored
+	fixes map[*types.Func]*analysis.SuggestedFix
+}
+
+func newHelperRefactorer(a *analyzer, r *reporter, scopeCol *scopeCollection) *helperRefactorer {
+	pass := r.pass
+
+	h := &helperRefactorer{
+		analyzer:   a,
+		pass:       pass,
+		scopeCol:   scopeCol,
+		decls:      map[*types.Func]*ast.FuncDecl{},
+		calls:      map[*types.Func][]*ast.CallExpr{},
+		referenced: map[*types.Func]bool{},
+		fixes:      map[*types.Func]*analysis.SuggestedFix{},
+	}
+
+	callees := map[*ast.Ident]*ast.CallExpr{}
+
+	for _, file := range pass.Files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.FuncDecl:
+				if fn, ok := pass.TypesInfo.Defs[n.Name].(*types.Func); ok {
+					h.decls[fn] = n
+				}
+
+			case *ast.CallExpr:
+				if id, ok := ast.Unparen(n.Fun).(*ast.Ident); ok {
+					callees[id] = n
+				}
+			}
+
+			return true
+		})
+	}
+
+	for id, obj := range pass.TypesInfo.Uses {
+		fn, ok := obj.(*types.Func)
+		if !ok || fn.Pkg() != pass.Pkg {
+			continue
+		}
+
+		if call, ok := callees[id]; ok {
+			h.calls[fn] = append(h.calls[fn], call)
+		} else {
+			h.referenced[fn] = true
+		}
+	}
+
+	for _, calls := range h.calls {
+		slices.SortFunc(calls, func(a, b *ast.CallExpr) int {
+			return int(a.Pos() - b.Pos())
+		})
+	}
+
+	return h
+}
+
+// fix returns the fix threading the test context through helper and the
+// helpers it is connected to, or nil if that is not possible.
+func (h *helperRefactorer) fix(helper *types.Func) *analysis.SuggestedFix {
+	if fix, ok := h.fixes[helper]; ok {
+		return fix
+	}
+
+	helpers := h.connectedHelpers(helper)
+
+	var fix *analysis.SuggestedFix
+	if helpers != nil {
+		fix = h.buildFix(helpers)
+	}
+
+	for _, fn := range append(helpers, helper) {
+		h.fixes[fn] = fix
+	}
+
+	return fix
+}
+
+// connectedHelpers returns helper along with the helpers of the package it
+// calls which create root contexts, and the functions calling any of them
+// outside of test scopes, as all of them need to take a context. It returns
+// nil if any of them cannot be refactored.
+func (h *helperRefactorer) connectedHelpers(helper *types.Func) []*types.Func {
+	var (
+		helpers []*types.Func
+		queue   = []*types.Func{helper}
+	)
+
+	for len(queue) > 0 {
+		fn := queue[0]
+		queue = queue[1:]
+
+		if slices.Contains(helpers, fn) {
+			continue
+		}
+
+		decl := h.decls[fn]
+		if !h.canRefactor(fn, decl) {
+			return nil
+		}
+
+		helpers = append(helpers, fn)
+
+		// Helpers called by fn
+		ast.Inspect(decl.Body, func(n ast.Node) bool {
+			if call, ok := n.(*ast.CallExpr); ok {
+				if callee := calleeFunc(h.pass.TypesInfo, call); callee != nil && callee.Pkg() == h.pass.Pkg &&
+					calleeRootContextFact(h.pass, call) != nil {
+					queue = append(queue, callee)
+				}
+			}
+
+			return true
+		})
+
+		// Functions calling fn outside of test scopes
+		for _, call := range h.calls[fn] {
+			if h.callSource(call) != nil {
+				continue
+			}
+
+			caller := h.enclosingFunc(call)
+			if caller == nil {
+				return nil
+			}
+
+			queue = append(queue, caller)
+		}
+	}
+
+	return helpers
+}
+
+// canRefactor reports whether a context parameter can be added to fn, which
+// requires fn to be an unexported function only called within the package,
+// with named parameters and no other use of the name ctx.
+func (h *helperRefactorer) canRefactor(fn *types.Func, decl *ast.FuncDecl) bool {
+	if decl == nil || decl.Recv != nil || decl.Body == nil || fn.Exported() ||
+		fn.Name() == "init" || fn.Name() == "main" || h.referenced[fn] {
+		return false
+	}
+
+	for _, field := range decl.Type.Params.List {
+		if len(field.Names) == 0 {
+			return false
+		}
+	}
+
+	var taken bool
+
+	ast.Inspect(decl, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && id.Name == "ctx" {
+			taken = true
+		}
+
+		return !taken
+	})
+
+	return !taken
+}
+
+// callSource returns the source of the test context at call, if call is
+// within a test scope.
+func (h *helperRefactorer) callSource(call *ast.CallExpr) *ContextSource {
+	s := h.scopeCol.findScope(call.Pos())
+	if s == nil {
+		return nil
+	}
+
+	source := s.contextSourceAt(h.pass.TypesInfo, h.pass.Pkg, call.Pos())
+	if source != nil && s.inCleanup() {
+		return cleanupSource(h.pass, fileOf(h.pass, call.Pos()), source)
+	}
+
+	return source
+}
+
+// enclosingFunc returns the function declared by the declaration containing
+// call, or nil.
+func (h *helperRefactorer) enclosingFunc(call *ast.CallExpr) *types.Func {
+	for fn, decl := range h.decls {
+		if decl.Pos() <= call.Pos() && call.End() <= decl.End() {
+			return fn
+		}
+	}
+
+	return nil
+}
+
+// buildFix returns the fix adding a context parameter to helpers and passing
+// contexts at their call sites.
+func (h *helperRefactorer) buildFix(helpers []*types.Func) *analysis.SuggestedFix {
+	var (
+		edits []analysis.TextEdit
+		names []string
+	)
+
+	for _, fn := range helpers {
+		decl := h.decls[fn]
+		file := fileOf(h.pass, decl.Pos())
+
+		contextName, importEdits, ok := importedName(h.pass, file, "context")
+		if !ok {
+			return nil
+		}
+
+		names = append(names, fn.Name())
+		edits = append(edits, importEdits...)
+		edits = append(edits, addParamEdit(decl.Type, "ctx "+contextName+".Context"))
+		edits = append(edits, h.replaceRootContexts(decl)...)
+
+		for _, call := range h.calls[fn] {
+			arg := "ctx"
+
+			if source := h.callSource(call); source != nil {
+				arg = source.Expr
+				edits = append(edits, source.Edits...)
+
+				if tp := source.param; tp != nil && tp.isUnnamed {
+					edits = append(edits, tp.nameEdits()...)
+				}
+			}
+
+			edits = append(edits, addArgEdit(call, arg))
+		}
+	}
+
+	return &analysis.SuggestedFix{
+		Message:   "add a ctx parameter to " + strings.Join(names, ", ") + " and pass the test context",
+		TextEdits: dedupeEdits(edits),
+	}
+}
+
+// replaceRootContexts returns the edits replacing the root contexts created
+// within decl with its ctx parameter.
+func (h *helperRefactorer) replaceRootContexts(decl *ast.FuncDecl) []analysis.TextEdit {
+	var edits []analysis.TextEdit
+
+	ast.Inspect(decl.Body, func(n ast.Node) bool {
+		if lit, ok := n.(*ast.FuncLit); ok {
+			if s := h.scopeCol.findScope(lit.Body.Pos()); s != nil && s.Node == lit && s.source != nil {
+				return false // test scopes are checked themselves
+			}
+		}
+
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+
+		if x, _, fn := forbiddenMethod(h.pass.TypesInfo, call, h.isForbidden); x != nil {
+			replacement := "ctx"
+			if template := h.forbiddenFuncs[fn.FullName()]; template != "" {
+				replacement = expandTemplate(h.pass.Fset, template, &ContextSource{Expr: "ctx"}, call)
+			}
+
+			edits = append(edits, analysis.TextEdit{Pos: call.Pos(), End: call.End(), NewText: []byte(replacement)})
+		}
+
+		return true
+	})
+
+	return edits
+}
+
+// addParamEdit returns an edit adding param as the first parameter of fnType.
+func addParamEdit(fnType *ast.FuncType, param string) analysis.TextEdit {
+	params := fnType.Params
+	if len(params.List) == 0 {
+		return analysis.TextEdit{Pos: params.Closing, End: params.Closing, NewText: []byte(param)}
+	}
+
+	return analysis.TextEdit{Pos: params.List[0].Pos(), End: params.List[0].Pos(), NewText: []byte(param + ", ")}
+}
+
+// addArgEdit returns an edit adding arg as the first argument of call.
+func addArgEdit(call *ast.CallExpr, arg string) analysis.TextEdit {
+	if len(call.Args) > 0 {
+		arg += ", "
+	}
+
+	return analysis.TextEdit{Pos: call.Lparen + 1, End: call.Lparen + 1, NewText: []byte(arg)}
+}
+
+// calleeFunc returns the function of the package-level function called by
+// call, or nil.
+func calleeFunc(info *types.Info, call *ast.CallExpr) *types.Func {
+	id, ok := ast.Unparen(call.Fun).(*ast.Ident)
+	if !ok {
+		return nil
+	}
+
+	fn, _ := info.Uses[id].(*types.Func)
+
+	return fn
+}
+
+// dedupeEdits removes duplicate edits, as edits like the naming of a
+// parameter may be required by several call sites.
+func dedupeEdits(edits []analysis.TextEdit) []analysis.TextEdit {
+	var result []analysis.TextEdit
+
+	for _, edit := range edits {
+		if !slices.ContainsFunc(result, func(other analysis.TextEdit) bool {
+			return other.Pos == edit.Pos && other.End == edit.End && string(other.NewText) == string(edit.NewText)
+		}) {
+			result = append(result, edit)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,58 @@
+package testctxlint
+
+import (
+	"go/ast"
+	"slices"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// reporter collects the diagnostics of a pass before reporting them, so that
+// edits depending on all fixes of a file can be added first.
+type reporter struct {
+	pass *analysis.Pass
+
+	// Import paths of packages which may end up unused once the calls to
+	// their functions have been replaced, such as the context package
+	removableImports []string
+
+	diagnostics []analysis.Diagnostic
+
+	// Nodes removed by the first fix of the diagnostic with the same index
+	removed [][]ast.Node
+}
+
+// report adds a diagnostic whose first suggested fix removes the given nodes,
+// unless it is suppressed by an ignore directive.
+func (r *reporter) report(diag analysis.Diagnostic, removed ...ast.Node) {
+	if r.suppressed(diag.Pos) {
+		return
+	}
+
+	r.diagnostics = append(r.diagnostics, diag)
+	r.removed = append(r.removed, removed)
+}
+
+// removable marks the package with the given import path as one which may
+// end up unused.
+func (r *reporter) removable(path string) {
+	if !slices.Contains(r.removableImports, path) {
+		r.removableImports = append(r.removableImports, path)
+	}
+}
+
+// flush reports all collected diagnostics. For each file, the fixes of the
+// diagnostics remove imports which are no longer used once all fixes for the
+// file have been applied. Each diagnostic is given a fix suppressing it,
+// following its other fixes, if any.
+func (r *reporter) flush() {
+	for _, file := range r.pass.Files {
+		r.removeUnusedImports(file)
+	}
+
+	for _, diag := range r.diagnostics {
+		diag.SuggestedFixes = append(diag.SuggestedFixes, suppressFix(r.pass.Fset, fileOf(r.pass, diag.Pos), &diag))
+
+		r.pass.Report(diag)
+	}
+}
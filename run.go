@@ -0,0 +1,213 @@
+package testctxlint
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// runResolver resolves the functions passed to t.Run to the function
+// literals of the package implementing them.
+type runResolver struct {
+	info *types.Info
+
+	// Function and method declarations of the package
+	decls map[*types.Func]*ast.FuncDecl
+
+	// Values assigned to variables of the package
+	values map[*types.Var][]ast.Expr
+}
+
+// subtestFuncs returns the function literals of the package which run as
+// subtests, as they are passed to t.Run, either directly or through local
+// variables, factory functions returning them, and method values, like
+//
+//	t.Run(tc.name, makeCase(tc))
+func subtestFuncs(inspect *inspector.Inspector, pass *analysis.Pass) map[*ast.FuncLit]bool {
+	r := &runResolver{
+		info:   pass.TypesInfo,
+		decls:  map[*types.Func]*ast.FuncDecl{},
+		values: map[*types.Var][]ast.Expr{},
+	}
+
+	var runCalls []*ast.CallExpr
+
+	inspect.Preorder([]ast.Node{
+		(*ast.FuncDecl)(nil),
+		(*ast.AssignStmt)(nil),
+		(*ast.ValueSpec)(nil),
+		(*ast.CallExpr)(nil),
+	}, func(n ast.Node) {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			if fn, ok := pass.TypesInfo.Defs[n.Name].(*types.Func); ok && n.Body != nil {
+				r.decls[fn] = n
+			}
+
+		case *ast.AssignStmt:
+			if n.Tok == token.DEFINE || n.Tok == token.ASSIGN {
+				r.addValues(n.Lhs, n.Rhs)
+			}
+
+		case *ast.ValueSpec:
+			names := make([]ast.Expr, len(n.Names))
+			for i, name := range n.Names {
+				names[i] = name
+			}
+
+			r.addValues(names, n.Values)
+
+		case *ast.CallExpr:
+			if funcFromBenchOrTestRunCall(pass.TypesInfo, n) != nil {
+				runCalls = append(runCalls, n)
+			}
+		}
+	})
+
+	lits := map[*ast.FuncLit]bool{}
+
+	for _, call := range runCalls {
+		for _, lit := range r.funcLits(call.Args[1], map[ast.Node]bool{}) {
+			lits[lit] = true
+		}
+	}
+
+	return lits
+}
+
+// addValues records the values assigned to variables by an assignment or
+// declaration with as many values as variables.
+func (r *runResolver) addValues(lhs, rhs []ast.Expr) {
+	if len(lhs) != len(rhs) {
+		return
+	}
+
+	for i, expr := range lhs {
+		id, ok := ast.Unparen(expr).(*ast.Ident)
+		if !ok {
+			continue
+		}
+
+		if v, ok := r.info.ObjectOf(id).(*types.Var); ok {
+			r.values[v] = append(r.values[v], rhs[i])
+		}
+	}
+}
+
+// funcLits returns the function literals expr evaluates to.
+func (r *runResolver) funcLits(expr ast.Expr, seen map[ast.Node]bool) []*ast.FuncLit {
+	expr = ast.Unparen(expr)
+	if seen[expr] {
+		return nil
+	}
+
+	seen[expr] = true
+
+	switch expr := expr.(type) {
+	case *ast.FuncLit:
+		return []*ast.FuncLit{expr}
+
+	case *ast.Ident:
+		var lits []*ast.FuncLit
+
+		if v, ok := r.info.Uses[expr].(*types.Var); ok {
+			for _, value := range r.values[v] {
+				lits = append(lits, r.funcLits(value, seen)...)
+			}
+		}
+
+		return lits
+
+	case *ast.CallExpr:
+		// Factory functions returning literals
+		var lits []*ast.FuncLit
+
+		for _, fn := range r.funcs(expr.Fun, seen) {
+			for _, result := range returnedValues(fn) {
+				lits = append(lits, r.funcLits(result, seen)...)
+			}
+		}
+
+		return lits
+	}
+
+	return nil
+}
+
+// funcs returns the function declarations and literals of the package the
+// function expr evaluates to, including those of method values.
+func (r *runResolver) funcs(expr ast.Expr, seen map[ast.Node]bool) []ast.Node {
+	expr = ast.Unparen(expr)
+
+	var id *ast.Ident
+
+	switch e := expr.(type) {
+	case *ast.Ident:
+		id = e
+	case *ast.SelectorExpr:
+		id = e.Sel
+	case *ast.FuncLit:
+		return []ast.Node{e}
+	default:
+		return nil
+	}
+
+	switch obj := r.info.Uses[id].(type) {
+	case *types.Func:
+		if decl := r.decls[obj.Origin()]; decl != nil {
+			return []ast.Node{decl}
+		}
+
+	case *types.Var:
+		// Variables holding functions or method values
+		if _, ok := expr.(*ast.Ident); !ok {
+			break
+		}
+
+		var fns []ast.Node
+
+		for _, value := range r.values[obj] {
+			if value = ast.Unparen(value); !seen[value] {
+				seen[value] = true
+				fns = append(fns, r.funcs(value, seen)...)
+			}
+		}
+
+		return fns
+	}
+
+	return nil
+}
+
+// returnedValues returns the values returned by the function declaration or
+// literal fn, if it returns a single value.
+func returnedValues(fn ast.Node) []ast.Expr {
+	var body *ast.BlockStmt
+
+	switch fn := fn.(type) {
+	case *ast.FuncDecl:
+		body = fn.Body
+	case *ast.FuncLit:
+		body = fn.Body
+	}
+
+	var results []ast.Expr
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false // returns of nested functions
+		case *ast.ReturnStmt:
+			if len(n.Results) == 1 {
+				results = append(results, n.Results[0])
+			}
+		}
+
+		return true
+	})
+
+	return results
+}
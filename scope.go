@@ -3,6 +3,7 @@ package testctxlint
 import (
 	"go/ast"
 	"go/token"
+	"go/types"
 	"sort"
 )
 
@@ -13,8 +14,23 @@ type scope struct {
 	// The function that declares this scope
 	funcType *ast.FuncType
 
+	// Source of the test context within this scope, or nil if it is
+	// inherited from the parent scope
+	source *ContextSource
+
 	// Parent scope or nil
 	parent *scope
+
+	// Whether this scope is a function registered through Cleanup
+	cleanup bool
+
+	// Whether this scope runs as a subtest of its parent scope, like a
+	// function passed to t.Run
+	subtest bool
+
+	// Whether this scope is a test function itself, whose context is
+	// canceled once it returns, unlike the context of a helper
+	test bool
 }
 
 func (s *scope) isAncestorOf(sub *scope) bool {
@@ -27,23 +43,106 @@ func (s *scope) isAncestorOf(sub *scope) bool {
 	return false
 }
 
-func (s *scope) findNearestBenchmarkOrTestParamWithInfo() *testingParam {
+// findNearestContextSource returns the context source of the innermost
+// scope, starting at s, which has one.
+func (s *scope) findNearestContextSource() *ContextSource {
 	for current := s; current != nil; current = current.parent {
-		if tp := benchmarkOrTestParamWithInfo(current.funcType); tp != nil {
-			return tp
+		if current.source != nil {
+			return current.source
 		}
 	}
 
 	return nil
 }
 
+// contextSourceAt returns the source of the context to use at pos within s.
+// A context.Context parameter or a variable holding the test context, or a
+// context derived from either, is preferred over the test's context, as it
+// may carry deadlines and values set by the caller and keeps the code
+// consistent.
+func (s *scope) contextSourceAt(info *types.Info, pkg *types.Package, pos token.Pos) *ContextSource {
+	test := s.sourceScope()
+	if test == nil {
+		return nil
+	}
+
+	if v := contextVarAt(info, pkg, test.Node, test.source, pos); v != nil {
+		return &ContextSource{Expr: v.Name()}
+	}
+
+	return test.source
+}
+
+// sourceScope returns the innermost scope, starting at s, which has its own
+// context source, or nil.
+func (s *scope) sourceScope() *scope {
+	for current := s; current != nil; current = current.parent {
+		if current.source != nil {
+			return current
+		}
+	}
+
+	return nil
+}
+
+// subtestParents returns the enclosing scopes with their own context sources
+// of the subtest s runs in, innermost first. It returns nil if s does not run
+// in a subtest.
+func (s *scope) subtestParents() []*scope {
+	test := s.sourceScope()
+	if test == nil || !test.subtest {
+		return nil
+	}
+
+	var parents []*scope
+
+	for p := test.parent; p != nil; p = p.parent {
+		if p.source != nil {
+			parents = append(parents, p)
+		}
+	}
+
+	return parents
+}
+
+// inCleanup reports whether code in s runs as part of a cleanup function of
+// the test whose context it inherits.
+func (s *scope) inCleanup() bool {
+	for current := s; current != nil; current = current.parent {
+		if current.cleanup {
+			return true
+		}
+
+		if current.source != nil {
+			return false
+		}
+	}
+
+	return false
+}
+
 // scopeCollection holds scopes sorted by position for efficient lookup
 type scopeCollection struct {
 	scopes []*scope
 	sorted bool
+
+	// Scope-defining nodes that have already been added
+	nodes map[ast.Node]bool
 }
 
+// add adds s to the collection unless a scope for the same node already
+// exists. A function literal passed to t.Run is seen both as a call argument
+// and as a function literal, but must only be added once.
 func (sc *scopeCollection) add(s *scope) {
+	if sc.nodes == nil {
+		sc.nodes = make(map[ast.Node]bool)
+	}
+
+	if sc.nodes[s.Node] {
+		return
+	}
+
+	sc.nodes[s.Node] = true
 	sc.scopes = append(sc.scopes, s)
 	sc.sorted = false
 }
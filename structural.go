@@ -0,0 +1,78 @@
+package testctxlint
+
+import (
+	"go/ast"
+	"go/types"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// contextMethodScopes is the provider for the -context-types flag. It
+// considers functions test scopes if they take a parameter of an allow-listed
+// type with a "Context() context.Context" method, such as a wrapper around
+// *testing.T.
+type contextMethodScopes struct {
+	a *analyzer
+}
+
+func (p contextMethodScopes) FuncScope(pass *analysis.Pass, fn ast.Node, _ *ast.CallExpr) *ContextSource {
+	if len(p.a.contextTypes) == 0 {
+		return nil
+	}
+
+	var fnType *ast.FuncType
+
+	switch fn := fn.(type) {
+	case *ast.FuncDecl:
+		fnType = fn.Type
+	case *ast.FuncLit:
+		fnType = fn.Type
+	}
+
+	for _, param := range fnType.Params.List {
+		typ := pass.TypesInfo.TypeOf(param.Type)
+		if typ == nil {
+			continue
+		}
+
+		named := namedOf(typ)
+		if named == nil || !p.allowed(named) || !hasContextMethod(typ) {
+			continue
+		}
+
+		tp := newTestingParam(pass.TypesInfo, fnType, param, strings.ToLower(named.Obj().Name()))
+
+		return tp.contextSource()
+	}
+
+	return nil
+}
+
+// allowed reports whether named or its package is in the allow list.
+func (p contextMethodScopes) allowed(named *types.Named) bool {
+	obj := named.Obj()
+	if obj.Pkg() == nil {
+		return false
+	}
+
+	return slices.Contains(p.a.contextTypes, obj.Pkg().Path()) ||
+		slices.Contains(p.a.contextTypes, obj.Pkg().Path()+"."+obj.Name())
+}
+
+// hasContextMethod reports whether values of typ have a method
+// "Context() context.Context".
+func hasContextMethod(typ types.Type) bool {
+	obj, _, _ := types.LookupFieldOrMethod(typ, true, nil, "Context")
+
+	fn, ok := obj.(*types.Func)
+	if !ok {
+		return false
+	}
+
+	sig := fn.Type().(*types.Signature)
+
+	return sig.Params().Len() == 0 && sig.Results().Len() == 1 &&
+		isContextType(sig.Results().At(0).Type())
+}
@@ -3,8 +3,11 @@ package testctxlint
 import (
 	"fmt"
 	"go/ast"
+	"go/format"
+	"go/token"
 	"go/types"
 	"os"
+	"slices"
 	"strings"
 
 	"golang.org/x/mod/semver"
@@ -15,12 +18,63 @@ import (
 )
 
 // Analyzer is the main instance of the testctxlinter analyzer.
-var Analyzer = &analysis.Analyzer{
-	Name:     "testctxlint",
-	Doc:      "check for any code where test context could be used but isn't",
-	Run:      run,
-	Requires: []*analysis.Analyzer{inspect.Analyzer},
-	URL:      "https://pkg.go.dev/github.com/icedream/testctxlint",
+var Analyzer = NewAnalyzer()
+
+// analyzer holds the configuration of an analyzer instance.
+type analyzer struct {
+	// Providers to consult for test scopes, in order
+	providers []ScopeProvider
+
+	// Packages and types whose Context method provides a test context
+	contextTypes stringListFlag
+
+	// Additional functions creating root contexts, with replacement templates
+	forbiddenFuncs forbiddenFuncsFlag
+
+	// Whether to suggest adding context parameters to helpers creating root
+	// contexts
+	refactorHelpers bool
+
+	// How fixes refer to the test context, inline or through a hoisted
+	// variable
+	fixStrategy fixStrategyFlag
+}
+
+// NewAnalyzer returns a new instance of the testctxlint analyzer. Next to the
+// built-in support for the testing package, testify suites and Ginkgo, it
+// recognizes test scopes through the given providers.
+func NewAnalyzer(providers ...ScopeProvider) *analysis.Analyzer {
+	a := &analyzer{}
+	a.providers = append([]ScopeProvider{
+		testingScopes{},
+		GinkgoProvider{},
+		contextMethodScopes{a},
+	}, providers...)
+
+	an := &analysis.Analyzer{
+		Name:      "testctxlint",
+		Doc:       "check for any code where test context could be used but isn't",
+		Run:       a.run,
+		Requires:  []*analysis.Analyzer{inspect.Analyzer},
+		FactTypes: []analysis.Fact{new(rootContextFact)},
+		URL:       "https://pkg.go.dev/github.com/icedream/testctxlint",
+	}
+
+	an.Flags.Var(&a.contextTypes, "context-types",
+		"comma-separated list of packages (example.com/testenv) and types (example.com/testenv.Env) "+
+			"whose Context method provides a test context when passed as a parameter")
+	an.Flags.Var(&a.forbiddenFuncs, "forbidden-funcs",
+		"additional function creating a root context, fully qualified (example.com/ctxutil.NewRoot), "+
+			"optionally followed by = and a replacement template (example.com/ctxutil.NewRoot=ctxutil.Wrap({ctx})) "+
+			"in which {ctx} is replaced by the test context and {args} by the call's arguments; may be repeated")
+	an.Flags.BoolVar(&a.refactorHelpers, "refactor-helpers", false,
+		"suggest adding a context parameter to helpers of the package creating root contexts, "+
+			"passing the test context at their call sites")
+	an.Flags.Var(&a.fixStrategy, "fix-strategy",
+		"how fixes refer to the test context: inline replaces each root context with the test context, "+
+			"hoist declares a ctx variable holding it at the start of the test and uses that instead")
+
+	return an
 }
 
 func goVersionAtLeast124(goVersion string) bool {
@@ -49,14 +103,24 @@ var inTest = len(os.Args) > 0 && strings.HasSuffix(strings.TrimSuffix(os.Args[0]
 // To pass analysis results between packages (and thus
 // potentially between address spaces), use Facts, which are
 // serializable.
-func run(pass *analysis.Pass) (interface{}, error) {
+func (a *analyzer) run(pass *analysis.Pass) (interface{}, error) {
+	// Facts are only reported for functions of the module of the package
+	// calling them, which is unknown outside of modules.
+	if pass.Module != nil && pass.Module.Path != "" {
+		a.exportRootContextFacts(pass)
+	}
+
 	if !shouldAnalyze(pass) {
 		return nil, nil
 	}
 
 	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
-	scopeCol := collectScopes(inspect, pass)
-	checkScopesForForbiddenCalls(pass, scopeCol)
+	scopeCol := a.collectScopes(inspect, pass)
+
+	r := &reporter{pass: pass}
+	a.checkScopesForForbiddenCalls(r, scopeCol)
+	a.checkPackageLevel(r, scopeCol)
+	r.flush()
 
 	return nil, nil
 }
@@ -69,68 +133,77 @@ func shouldAnalyze(pass *analysis.Pass) bool {
 		}
 	}
 
-	if !imports(pass.Pkg, "context") {
-		// package is not even using the context package
+	if !imports(pass.Pkg, "context") && len(pass.AllObjectFacts()) == 0 {
+		// package is neither using the context package nor functions
+		// creating root contexts
 		return false
 	}
 
 	return true
 }
 
-func collectScopes(inspect *inspector.Inspector, pass *analysis.Pass) *scopeCollection {
+func (a *analyzer) collectScopes(inspect *inspector.Inspector, pass *analysis.Pass) *scopeCollection {
 	scopeCol := &scopeCollection{}
+	cleanups := cleanupParams(pass)
+	subtests := subtestFuncs(inspect, pass)
+
+	addScope := func(node ast.Node, funcType *ast.FuncType, source *ContextSource, call *ast.CallExpr) {
+		lit, _ := node.(*ast.FuncLit)
+
+		scopeCol.add(&scope{
+			Node:     node,
+			funcType: funcType,
+			source:   source,
+			parent:   scopeCol.findScope(node.Pos()),
+			subtest:  source != nil && subtests[lit],
+			test:     source != nil && (subtests[lit] || isTestFunc(pass.TypesInfo, node, call)),
+		})
+	}
 
-	inspect.Nodes([]ast.Node{
+	inspect.WithStack([]ast.Node{
 		(*ast.CallExpr)(nil),
 		(*ast.FuncDecl)(nil),
 		(*ast.FuncLit)(nil),
 		(*ast.GoStmt)(nil),
-	}, func(node ast.Node, push bool) bool {
+	}, func(node ast.Node, push bool, stack []ast.Node) bool {
 		if !push {
 			return false
 		}
 
 		switch node := node.(type) {
 		case *ast.FuncLit:
-			if result := benchmarkOrTestParam(node.Type); result != nil {
+			call := callWithArg(stack, node)
+			if source := a.funcScope(pass, node, call); source != nil {
+				addScope(node, node.Type, source, call)
+			} else if source := handlerSource(pass.TypesInfo, scopeCol, node); source != nil {
+				addScope(node, node.Type, source, nil)
+			} else if call != nil &&
+				registersCleanupArg(pass.TypesInfo, cleanups, call, slices.Index(call.Args, ast.Expr(node))) {
 				scopeCol.add(&scope{
 					Node:     node,
 					funcType: node.Type,
 					parent:   scopeCol.findScope(node.Pos()),
+					cleanup:  true,
 				})
 			}
 
 		case *ast.FuncDecl:
-			if result := benchmarkOrTestParam(node.Type); result != nil {
-				scopeCol.add(&scope{
-					Node:     node,
-					funcType: node.Type,
-					parent:   scopeCol.findScope(node.Pos()),
-				})
+			if source := a.funcScope(pass, node, nil); source != nil {
+				addScope(node, node.Type, source, nil)
 			}
 
 		case *ast.GoStmt:
 			f := funcFromGoAsyncCall(node)
 			if funcLit, ok := f.(*ast.FuncLit); ok {
-				scopeCol.add(&scope{
-					Node:     funcLit,
-					funcType: funcLit.Type,
-					parent:   scopeCol.findScope(funcLit.Pos()),
-				})
+				addScope(funcLit, funcLit.Type, a.funcScope(pass, funcLit, nil), nil)
 			}
 
 		case *ast.CallExpr:
 			if f := funcFromBenchOrTestRunCall(pass.TypesInfo, node); f != nil {
 				if funcLit, ok := f.(*ast.FuncLit); ok {
-					scopeCol.add(&scope{
-						Node:     funcLit,
-						funcType: funcLit.Type,
-						parent:   scopeCol.findScope(funcLit.Pos()),
-					})
+					addScope(funcLit, funcLit.Type, a.funcScope(pass, funcLit, node), node)
 				}
 			}
-
-			return false
 		}
 
 		return true
@@ -139,14 +212,114 @@ func collectScopes(inspect *inspector.Inspector, pass *analysis.Pass) *scopeColl
 	return scopeCol
 }
 
-func checkScopesForForbiddenCalls(pass *analysis.Pass, scopeCol *scopeCollection) {
+// funcScope asks the providers whether fn starts a test scope and returns
+// the first context source found, or nil.
+func (a *analyzer) funcScope(pass *analysis.Pass, fn ast.Node, call *ast.CallExpr) *ContextSource {
+	for _, provider := range a.providers {
+		if source := provider.FuncScope(pass, fn, call); source != nil {
+			return source
+		}
+	}
+
+	return nil
+}
+
+// callWithArg returns the call expression which the last node on stack is
+// directly passed to as an argument, or nil.
+func callWithArg(stack []ast.Node, arg ast.Node) *ast.CallExpr {
+	if len(stack) < 2 {
+		return nil
+	}
+
+	call, ok := stack[len(stack)-2].(*ast.CallExpr)
+	if !ok {
+		return nil
+	}
+
+	for _, a := range call.Args {
+		if a == arg {
+			return call
+		}
+	}
+
+	return nil
+}
+
+func (a *analyzer) checkScopesForForbiddenCalls(r *reporter, scopeCol *scopeCollection) {
+	// Sort before iterating, as findScope would otherwise reorder the scopes
+	// while we are iterating over them
+	scopeCol.ensureSorted()
+
+	ctxVars := testContextVars(r.pass, a.isForbidden)
+
+	var refactorer *helperRefactorer
+	if a.refactorHelpers {
+		refactorer = newHelperRefactorer(a, r, scopeCol)
+	}
+
 	for _, s := range scopeCol.scopes {
-		checkScopeForForbiddenCalls(pass, s, scopeCol)
+		a.checkScopeForForbiddenCalls(r, s, scopeCol, ctxVars, refactorer)
 	}
 }
 
+// scopeChecker checks the nodes of a single scope.
+type scopeChecker struct {
+	*analyzer
+
+	r        *reporter
+	s        *scope
+	scopeCol *scopeCollection
+
+	// Whether s runs as part of a cleanup function
+	cleanup bool
+
+	// Enclosing test scopes whose contexts must not be used within s, if s
+	// runs as part of a subtest
+	parents []*scope
+
+	// Variables holding test contexts along with their uses, see
+	// testContextVars
+	ctxVars map[*types.Var][]*ast.Ident
+
+	// Contexts already wrapped by context.WithoutCancel
+	uncanceled map[ast.Expr]bool
+
+	// Identifiers being assigned to
+	assigned map[*ast.Ident]bool
+
+	// Cancel idioms by the calls creating their root contexts
+	cancelIdioms map[*ast.CallExpr]*cancelIdiom
+
+	// Refactorer of helpers creating root contexts, if enabled
+	refactorer *helperRefactorer
+}
+
 // checkScopeForForbiddenCalls checks a single scope for forbidden context calls
-func checkScopeForForbiddenCalls(pass *analysis.Pass, s *scope, scopeCol *scopeCollection) {
+func (a *analyzer) checkScopeForForbiddenCalls(
+	r *reporter, s *scope, scopeCol *scopeCollection, ctxVars map[*types.Var][]*ast.Ident, refactorer *helperRefactorer,
+) {
+	c := &scopeChecker{
+		analyzer:   a,
+		r:          r,
+		s:          s,
+		scopeCol:   scopeCol,
+		cleanup:    s.inCleanup(),
+		ctxVars:    ctxVars,
+		uncanceled: map[ast.Expr]bool{},
+		assigned:   map[*ast.Ident]bool{},
+		refactorer: refactorer,
+
+		cancelIdioms: map[*ast.CallExpr]*cancelIdiom{},
+	}
+
+	if !c.cleanup {
+		c.parents = s.subtestParents()
+	}
+
+	if s.source != nil {
+		c.findTestCancelIdioms()
+	}
+
 	// Use ast.Inspect for more efficient traversal of just this scope's subtree
 	ast.Inspect(s.Node, func(n ast.Node) bool {
 		if n == nil || n == s.Node {
@@ -158,63 +331,186 @@ func checkScopeForForbiddenCalls(pass *analysis.Pass, s *scope, scopeCol *scopeC
 			return false // will be handled when processing that scope
 		}
 
-		call, ok := n.(*ast.CallExpr)
-		if !ok {
-			return true
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			for _, lhs := range n.Lhs {
+				if id, ok := ast.Unparen(lhs).(*ast.Ident); ok {
+					c.assigned[id] = true
+				}
+			}
+
+		case *ast.Ident:
+			c.checkParentContextVar(n)
+
+		case *ast.CallExpr:
+			c.checkCall(n)
 		}
 
-		x, sel, fn := forbiddenMethod(pass.TypesInfo, call)
-		if x == nil {
-			return true
+		return true
+	})
+}
+
+// checkCall checks a call within the scope.
+func (c *scopeChecker) checkCall(call *ast.CallExpr) {
+	pass := c.r.pass
+
+	if c.cleanup {
+		if fn := typeutil.StaticCallee(pass.TypesInfo, call); fn != nil && len(call.Args) == 1 &&
+			isFunctionNamed(fn, "context", "WithoutCancel") {
+			c.uncanceled[ast.Unparen(call.Args[0])] = true
+		} else if !c.uncanceled[call] && isTestingContextCall(pass.TypesInfo, call) {
+			reportCanceledContext(c.r, call)
 		}
+	}
 
-		forbidden := formatMethod(sel, fn)
+	c.checkParentContextCall(call)
 
-		tbInfo := s.findNearestBenchmarkOrTestParamWithInfo()
-		if tbInfo == nil {
-			return true
+	x, sel, fn := forbiddenMethod(pass.TypesInfo, call, c.isForbidden)
+	if x == nil {
+		if !c.cleanup {
+			c.checkRootContextHelperCall(call)
 		}
 
-		reportForbiddenCall(pass, call, forbidden, tbInfo)
+		return
+	}
 
-		return true
-	})
-}
+	forbidden := formatMethod(sel, fn)
 
-func reportForbiddenCall(pass *analysis.Pass, call *ast.CallExpr, forbidden string, tbInfo *testingParam) {
-	message := "replace " + forbidden + " with " + tbInfo.ident.Name + ".Context"
-	edits := []analysis.TextEdit{
-		{
-			// Replace context creation call
-			Pos:     call.Pos(),
-			End:     call.End(),
-			NewText: []byte(tbInfo.ident.Name + ".Context()"),
-		},
+	source := c.s.contextSourceAt(pass.TypesInfo, pass.Pkg, call.Pos())
+	if source == nil {
+		return
 	}
 
-	if tbInfo.isUnnamed {
-		message = "name parameter as " + tbInfo.ident.Name + " and " + message
-		edits = append([]analysis.TextEdit{
+	// The cancel idiom collapses into a declaration of the context itself
+	if c.fixStrategy == fixHoist && c.cancelIdioms[call] == nil {
+		source = c.hoistedSource(source)
+	}
+
+	if c.cleanup {
+		// Within cleanup functions, the test context has already been
+		// canceled, so only its values can be used.
+		source = cleanupSource(pass, fileOf(pass, call.Pos()), source)
+		if source == nil {
+			return
+		}
+	}
+
+	replacement := source.Expr
+
+	if template := c.forbiddenFuncs[fn.FullName()]; template != "" {
+		replacement = expandTemplate(pass.Fset, template, source, call)
+	}
+
+	c.r.removable(fn.Pkg().Path())
+
+	diagMessage := fmt.Sprintf("call to %s from a test routine", forbidden)
+	if source.Reason != "" {
+		diagMessage += " (" + source.Reason + ")"
+	}
+
+	var alternatives []analysis.SuggestedFix
+	if !c.cleanup {
+		alternatives = c.outlivingFixes(call, fn, forbidden, source)
+	}
+
+	if idiom := c.cancelIdioms[call]; idiom != nil && !c.cleanup && replacement == source.Expr {
+		reportCancelIdiom(c.r, call, diagMessage, source, replacement, idiom, alternatives...)
+
+		return
+	}
+
+	reportReplacement(c.r, call, diagMessage, forbidden, source, replacement, alternatives...)
+}
+
+// reportReplacement reports node, described by replaced, and suggests
+// replacing it with replacement, which refers to source, or the alternatives.
+func reportReplacement(
+	r *reporter, node ast.Node, diagMessage, replaced string, source *ContextSource, replacement string,
+	alternatives ...analysis.SuggestedFix,
+) {
+	reportFix(r, node, diagMessage, "replace "+replaced+" with "+strings.TrimSuffix(replacement, "()"), source,
+		[]analysis.TextEdit{
 			{
-				// Add parameter name before the type
-				Pos:     tbInfo.param.Type.Pos(),
-				End:     tbInfo.param.Type.Pos(),
-				NewText: []byte(tbInfo.ident.Name + " "),
+				// Replace context creation call
+				Pos:     node.Pos(),
+				End:     node.End(),
+				NewText: []byte(replacement),
 			},
-		}, edits...)
+		}, []ast.Node{node}, alternatives...)
+}
+
+// reportFix reports node with a fix consisting of edits, which remove the
+// given nodes and refer to source, followed by the alternative fixes.
+func reportFix(
+	r *reporter, node ast.Node, diagMessage, message string, source *ContextSource,
+	edits []analysis.TextEdit, removed []ast.Node, alternatives ...analysis.SuggestedFix,
+) {
+	// Edits shared with the fixes of other diagnostics, like the naming of a
+	// parameter, are part of each of them, so that each fix can be applied on
+	// its own. Once merged, identical edits are applied only once.
+	if tbInfo := source.param; tbInfo != nil && tbInfo.isUnnamed {
+		message = "name parameter as " + tbInfo.ident.Name + " and " + message
+		edits = append(tbInfo.nameEdits(), edits...)
 	}
 
-	pass.Report(analysis.Diagnostic{
-		Pos:     call.Pos(),
-		End:     call.End(),
-		Message: fmt.Sprintf("call to %s from a test routine", forbidden),
-		SuggestedFixes: []analysis.SuggestedFix{
+	edits = append(edits, source.Edits...)
+	edits = append(edits, source.shared...)
+
+	r.report(analysis.Diagnostic{
+		Pos:     node.Pos(),
+		End:     node.End(),
+		Message: diagMessage,
+		SuggestedFixes: append([]analysis.SuggestedFix{
 			{
 				Message:   message,
 				TextEdits: edits,
 			},
-		},
-	})
+		}, alternatives...),
+	}, removed...)
+}
+
+// reportCanceledContext reports the use of the test context from call within
+// a cleanup function.
+func reportCanceledContext(r *reporter, call *ast.CallExpr) {
+	name, edits, ok := importedName(r.pass, fileOf(r.pass, call.Pos()), "context")
+
+	diag := analysis.Diagnostic{
+		Pos:     call.Pos(),
+		End:     call.End(),
+		Message: fmt.Sprintf("call to %s from a cleanup function (%s)", types.ExprString(call), cleanupReason),
+	}
+
+	if ok {
+		diag.SuggestedFixes = []analysis.SuggestedFix{
+			{
+				Message: "wrap with " + name + ".WithoutCancel",
+				TextEdits: append(edits,
+					analysis.TextEdit{
+						Pos:     call.Pos(),
+						End:     call.Pos(),
+						NewText: []byte(name + ".WithoutCancel("),
+					},
+					analysis.TextEdit{
+						Pos:     call.End(),
+						End:     call.End(),
+						NewText: []byte(")"),
+					}),
+			},
+		}
+	}
+
+	r.report(diag)
+}
+
+// fileOf returns the file of pass containing pos.
+func fileOf(pass *analysis.Pass, pos token.Pos) *ast.File {
+	for _, file := range pass.Files {
+		if file.FileStart <= pos && pos <= file.FileEnd {
+			return file
+		}
+	}
+
+	return nil
 }
 
 func formatMethod(sel *types.Selection, fn *types.Func) string {
@@ -236,7 +532,9 @@ func formatMethod(sel *types.Selection, fn *types.Func) string {
 // forbiddenMethod decomposes a call x.m() into (x, x.m, m) where
 // x is a variable/pkgName, x.m is a selection, and m is the static callee m.
 // Returns (nil, nil, nil) if call is not of this form.
-func forbiddenMethod(info *types.Info, call *ast.CallExpr) (types.Object, *types.Selection, *types.Func) {
+func forbiddenMethod(
+	info *types.Info, call *ast.CallExpr, isForbidden func(*types.Func) bool,
+) (types.Object, *types.Selection, *types.Func) {
 	// Compare to typeutil.StaticCallee.
 	fun := ast.Unparen(call.Fun)
 	e := call.Fun
@@ -266,7 +564,7 @@ func forbiddenMethod(info *types.Info, call *ast.CallExpr) (types.Object, *types
 		}
 	}
 
-	if !isContextCreationFn(fn) {
+	if !isForbidden(fn) {
 		return nil, nil, nil
 	}
 
@@ -292,110 +590,378 @@ type testingParam struct {
 	ident     *ast.Ident
 	isUnnamed bool
 	param     *ast.Field // The original parameter for unnamed params
+
+	// Selector path from ident to the testing handle, such as ".t" for a
+	// fixture struct receiver holding the handle in field t. Empty if ident
+	// is the handle itself or the handle is embedded and thus promotes its
+	// Context method.
+	selector string
+
+	// The function whose parameter or receiver is unnamed
+	fnType *ast.FuncType
+
+	// Explanation for the choice of this handle, added to the diagnostic
+	reason string
+}
+
+// expr returns the expression that evaluates to the testing handle.
+func (tp *testingParam) expr() string {
+	return tp.ident.Name + tp.selector
+}
+
+// object returns the variable of the testing handle, or nil if it is unnamed
+// or reached through a selector.
+func (tp *testingParam) object(info *types.Info) types.Object {
+	if tp.isUnnamed || tp.selector != "" {
+		return nil
+	}
+
+	return info.Defs[tp.ident]
 }
 
-func benchmarkOrTestParam(fnTypeDecl *ast.FuncType) *ast.Ident {
-	// Check that the function's arguments include "*testing.T" or "*testing.B".
+// contextSource returns the source for the context of the testing handle.
+func (tp *testingParam) contextSource() *ContextSource {
+	return &ContextSource{
+		Expr:   tp.expr() + ".Context()",
+		Reason: tp.reason,
+		param:  tp,
+	}
+}
+
+func benchmarkOrTestParamWithInfo(info *types.Info, fnTypeDecl *ast.FuncType) *testingParam {
+	// Check that the function's arguments include "*testing.T", "*testing.B",
+	// "*testing.F" or "testing.TB".
 	params := fnTypeDecl.Params.List
 
 	for _, param := range params {
-		if testingType, ok := typeIsTestingDotTOrB(param.Type); ok {
-			if len(param.Names) > 0 {
-				return param.Names[0]
-			}
-			// Handle unnamed testing parameters by creating a synthetic identifier
-			// with a conventional name based on the testing type
-			var name string
-
-			switch testingType {
-			case "T":
-				name = "t"
-			case "B":
-				name = "b"
-			}
-
-			return &ast.Ident{
-				Name: name,
-				// Use the position of the type for the synthetic identifier
-				NamePos: param.Type.Pos(),
-			}
+		if testingType, ok := typeIsTestingDotTOrB(info, param.Type); ok {
+			// Unnamed testing parameters are named based on the testing type
+			return newTestingParam(info, fnTypeDecl, param, strings.ToLower(testingType))
 		}
 	}
 
 	return nil
 }
 
-func benchmarkOrTestParamWithInfo(fnTypeDecl *ast.FuncType) *testingParam {
-	// Check that the function's arguments include "*testing.T" or "*testing.B".
-	params := fnTypeDecl.Params.List
+// methodTestingParam returns the testing handle a method obtains through its
+// receiver, or nil if there is none.
+func methodTestingParam(info *types.Info, decl *ast.FuncDecl) *testingParam {
+	if tp := receiverTestingParam(info, decl); tp != nil {
+		return tp
+	}
 
-	for _, param := range params {
-		if testingType, ok := typeIsTestingDotTOrB(param.Type); ok {
-			if len(param.Names) > 0 {
-				return &testingParam{
-					ident:     param.Names[0],
-					isUnnamed: false,
-					param:     param,
-				}
-			}
-			// Handle unnamed testing parameters by creating a synthetic identifier
-			// with a conventional name based on the testing type
-			var name string
-
-			switch testingType {
-			case "T":
-				name = "t"
-			case "B":
-				name = "b"
-			}
+	return suiteTestingParam(info, decl)
+}
 
-			return &testingParam{
-				ident: &ast.Ident{
-					Name: name,
-					// Use the position of the type for the synthetic identifier
-					NamePos: param.Type.Pos(),
-				},
-				isUnnamed: true,
-				param:     param,
-			}
+// receiverTestingParam returns the testing handle held by the receiver of
+// method decl, such as the t field in
+//
+//	type fixture struct {
+//		t  *testing.T
+//		db *sql.DB
+//	}
+//
+// Embedded handles and handles held by embedded structs are found as well.
+// Returns nil if decl is not a method or its receiver holds no handle.
+func receiverTestingParam(info *types.Info, decl *ast.FuncDecl) *testingParam {
+	if decl.Recv == nil || len(decl.Recv.List) != 1 {
+		return nil
+	}
+
+	recv := decl.Recv.List[0]
+
+	recvType := info.TypeOf(recv.Type)
+	if recvType == nil {
+		return nil
+	}
+
+	named := namedOf(recvType)
+	if named == nil {
+		return nil
+	}
+
+	selector, ok := testingFieldSelector(named)
+	if !ok {
+		return nil
+	}
+
+	return receiverParam(info, decl, recv, named, selector)
+}
+
+// receiverParam returns the receiver recv of method decl of named type named
+// as a testing handle reached through selector.
+func receiverParam(
+	info *types.Info, decl *ast.FuncDecl, recv *ast.Field, named *types.Named, selector string,
+) *testingParam {
+	// Unnamed receivers are named after the receiver type, as is conventional
+	// for receivers
+	tp := newTestingParam(info, decl.Type, recv, strings.ToLower(named.Obj().Name()[:1]))
+	tp.selector = selector
+
+	return tp
+}
+
+// suiteTestingParam returns the testing handle of a testify suite method,
+// which is obtained through the suite's T method:
+//
+//	func (s *MySuite) TestX() {
+//		doSomething(s.T().Context())
+//	}
+//
+// Only test methods and the lifecycle hooks called by suite.Run are
+// considered, as other methods may be called while no test is running.
+func suiteTestingParam(info *types.Info, decl *ast.FuncDecl) *testingParam {
+	if decl.Recv == nil || len(decl.Recv.List) != 1 {
+		return nil
+	}
+
+	var suiteLevel bool
+
+	switch name := decl.Name.Name; {
+	case strings.HasPrefix(name, "Test"),
+		name == "SetupTest", name == "TearDownTest",
+		name == "SetupSubTest", name == "TearDownSubTest",
+		name == "BeforeTest", name == "AfterTest":
+		// s.T() is the currently running test or subtest
+
+	case name == "SetupSuite", name == "TearDownSuite", name == "HandleStats":
+		// These hooks run outside of the suite's tests, where s.T() is the
+		// test which called suite.Run.
+		suiteLevel = true
+
+	default:
+		return nil
+	}
+
+	recv := decl.Recv.List[0]
+
+	recvType := info.TypeOf(recv.Type)
+	if recvType == nil {
+		return nil
+	}
+
+	named := namedOf(recvType)
+	if named == nil || !hasSuiteTMethod(recvType, named.Obj().Pkg()) {
+		return nil
+	}
+
+	tp := receiverParam(info, decl, recv, named, ".T()")
+	if suiteLevel {
+		tp.reason = tp.expr() + " is the test running the whole suite in " + decl.Name.Name +
+			", so its context is canceled after all tests of the suite have finished"
+	}
+
+	return tp
+}
+
+// hasSuiteTMethod reports whether typ has a method "T() *testing.T", like
+// types embedding testify's suite.Suite.
+func hasSuiteTMethod(typ types.Type, pkg *types.Package) bool {
+	obj, _, _ := types.LookupFieldOrMethod(typ, true, pkg, "T")
+
+	fn, ok := obj.(*types.Func)
+	if !ok {
+		return false
+	}
+
+	sig := fn.Type().(*types.Signature)
+	if sig.Params().Len() != 0 || sig.Results().Len() != 1 {
+		return false
+	}
+
+	name, ok := testingTypeName(sig.Results().At(0).Type())
+
+	return ok && name == "T"
+}
+
+// namedOf returns the named type of typ, dereferencing pointers, or nil if
+// typ is not (a pointer to) a named type.
+func namedOf(typ types.Type) *types.Named {
+	if p, ok := types.Unalias(typ).(*types.Pointer); ok {
+		typ = p.Elem()
+	}
+
+	named, _ := types.Unalias(typ).(*types.Named)
+
+	return named
+}
+
+// testingFieldSelector finds a testing handle held by the struct type named
+// and returns the selector path leading to it from a value of that type.
+func testingFieldSelector(named *types.Named) (string, bool) {
+	pkg := named.Obj().Pkg()
+
+	// An embedded handle promotes its Context method, unless it is shadowed
+	// or ambiguous.
+	obj, _, _ := types.LookupFieldOrMethod(named, true, pkg, "Context")
+	if fn, ok := obj.(*types.Func); ok && fn.Pkg() != nil && fn.Pkg().Path() == "testing" {
+		return "", true
+	}
+
+	for _, field := range testingFields(named, map[*types.Named]bool{}) {
+		// Only use fields which can be selected by their name unambiguously
+		obj, _, _ := types.LookupFieldOrMethod(named, true, pkg, field.Name())
+		if obj == field {
+			return "." + field.Name(), true
 		}
 	}
 
-	return nil
+	return "", false
 }
 
-func typeIsTestingDotTOrB(expr ast.Expr) (string, bool) {
-	starExpr, ok := expr.(*ast.StarExpr)
+// testingFields returns the non-embedded fields of struct type named and its
+// embedded structs which hold a testing handle, shallowest first.
+func testingFields(named *types.Named, seen map[*types.Named]bool) []*types.Var {
+	if seen[named] {
+		return nil
+	}
+
+	seen[named] = true
+
+	st, ok := named.Underlying().(*types.Struct)
 	if !ok {
+		return nil
+	}
+
+	var fields, embedded []*types.Var
+
+	for field := range st.Fields() {
+		switch _, ok := testingTypeName(field.Type()); {
+		case ok && !field.Embedded():
+			fields = append(fields, field)
+		case !ok && field.Embedded():
+			embedded = append(embedded, field)
+		}
+	}
+
+	for _, field := range embedded {
+		if sub := namedOf(field.Type()); sub != nil {
+			fields = append(fields, testingFields(sub, seen)...)
+		}
+	}
+
+	return fields
+}
+
+// typeIsTestingDotTOrB reports whether the type of expr is *testing.T,
+// *testing.B, *testing.F or testing.TB and returns the name of the matched
+// type.
+//
+// The type is resolved through info, so it does not matter under which name
+// the testing package has been imported.
+func typeIsTestingDotTOrB(info *types.Info, expr ast.Expr) (string, bool) {
+	typ := info.TypeOf(expr)
+	if typ == nil {
 		return "", false
 	}
 
-	selExpr, ok := starExpr.X.(*ast.SelectorExpr)
+	return testingTypeName(typ)
+}
+
+// testingTypeName returns the name of typ if it is *testing.T, *testing.B,
+// *testing.F or testing.TB. Type parameters constrained by testing.TB are
+// reported as testing.TB.
+func testingTypeName(typ types.Type) (string, bool) {
+	if tparam, ok := types.Unalias(typ).(*types.TypeParam); ok {
+		if constraintHasTestingContext(tparam) {
+			return "TB", true
+		}
+
+		return "", false
+	}
+
+	isPointer := false
+	if p, ok := types.Unalias(typ).(*types.Pointer); ok {
+		isPointer = true
+		typ = p.Elem()
+	}
+
+	named, ok := types.Unalias(typ).(*types.Named)
 	if !ok {
 		return "", false
 	}
 
-	varPkg := selExpr.X.(*ast.Ident)
-	if varPkg.Name != "testing" {
+	obj := named.Obj()
+	if obj.Pkg() == nil || obj.Pkg().Path() != "testing" {
 		return "", false
 	}
 
-	varTypeName := selExpr.Sel.Name
-	ok = varTypeName == "B" || varTypeName == "T"
+	name := obj.Name()
+	if isPointer {
+		ok = name == "B" || name == "F" || name == "T"
+	} else {
+		// testing.TB is an interface and thus never used as a pointer
+		ok = name == "TB"
+	}
+
+	return name, ok
+}
+
+// constraintHasTestingContext reports whether the type set of tparam's
+// constraint includes the Context method of testing.TB, as is the case for
+// [T testing.TB] or [S interface{ testing.TB; Parallel() }].
+func constraintHasTestingContext(tparam *types.TypeParam) bool {
+	iface, ok := tparam.Constraint().Underlying().(*types.Interface)
+	if !ok {
+		return false
+	}
+
+	for i := range iface.NumMethods() {
+		m := iface.Method(i)
+		if m.Name() != "Context" || m.Pkg() == nil || m.Pkg().Path() != "testing" {
+			continue
+		}
+
+		sig := m.Type().(*types.Signature)
+		if sig.Params().Len() == 0 && sig.Results().Len() == 1 &&
+			isContextType(sig.Results().At(0).Type()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isContextType reports whether typ is context.Context.
+func isContextType(typ types.Type) bool {
+	named, ok := types.Unalias(typ).(*types.Named)
+	if !ok {
+		return false
+	}
+
+	obj := named.Obj()
 
-	return varTypeName, ok
+	return obj.Pkg() != nil && obj.Pkg().Path() == "context" && obj.Name() == "Context"
 }
 
+// isForbidden reports whether fn creates a root context, either because it is
+// one of the standard functions or because it has been configured.
+func (a *analyzer) isForbidden(fn *types.Func) bool {
+	if isContextCreationFn(fn) {
+		return true
+	}
+
+	_, ok := a.forbiddenFuncs[fn.FullName()]
+
+	return ok
+}
+
+// xnetContextPath is the import path of the pre-Go 1.7 context package.
+const xnetContextPath = "golang.org/x/net/context"
+
 // isContextCreationFn reports whether the given func reference points to:
 // - context.TODO
 // - context.Background
+// - golang.org/x/net/context.TODO
+// - golang.org/x/net/context.Background
 func isContextCreationFn(fn *types.Func) bool {
 	if fn == nil {
 		panic("got nil for isContextCreationFn")
 	}
 
 	return isFunctionNamed(fn, "context", "TODO", "Background") ||
-		isMethodNamed(fn, "context", "TODO", "Background")
+		isMethodNamed(fn, "context", "TODO", "Background") ||
+		isFunctionNamed(fn, xnetContextPath, "TODO", "Background")
 }
 
 // isMethodNamed reports when a function f is a method,
@@ -450,3 +1016,54 @@ func funcFromBenchOrTestRunCall(info *types.Info, call *ast.CallExpr) ast.Expr {
 
 	return fun
 }
+
+// isTestFunc reports whether fn, which is passed to call if not nil, is a
+// test, benchmark or fuzz test function or a fuzz target. Subtests are
+// recorded by subtestFuncs instead.
+func isTestFunc(info *types.Info, fn ast.Node, call *ast.CallExpr) bool {
+	switch fn := fn.(type) {
+	case *ast.FuncDecl:
+		params := fn.Type.Params.List
+		if fn.Recv != nil || len(params) != 1 || len(params[0].Names) > 1 {
+			return false
+		}
+
+		typ, ok := typeIsTestingDotTOrB(info, params[0].Type)
+
+		return ok && (typ == "T" && strings.HasPrefix(fn.Name.Name, "Test") ||
+			typ == "B" && strings.HasPrefix(fn.Name.Name, "Benchmark") ||
+			typ == "F" && strings.HasPrefix(fn.Name.Name, "Fuzz"))
+
+	case *ast.FuncLit:
+		if call == nil {
+			return false
+		}
+
+		fuzz, ok := typeutil.Callee(info, call).(*types.Func)
+
+		return ok && isMethodNamed(fuzz, "testing", "Fuzz")
+	}
+
+	return false
+}
+
+// expandTemplate expands a replacement template configured for a function
+// creating a root context. {ctx} is replaced by the context expression of
+// source and {args} by the arguments of call.
+func expandTemplate(fset *token.FileSet, template string, source *ContextSource, call *ast.CallExpr) string {
+	args := make([]string, len(call.Args))
+
+	for i, arg := range call.Args {
+		var buf strings.Builder
+		if err := format.Node(&buf, fset, arg); err == nil {
+			args[i] = buf.String()
+		} else {
+			args[i] = types.ExprString(arg)
+		}
+	}
+
+	return strings.NewReplacer(
+		"{ctx}", source.Expr,
+		"{args}", strings.Join(args, ", "),
+	).Replace(template)
+}
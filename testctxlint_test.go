@@ -1,9 +1,16 @@
 package testctxlint_test
 
 import (
+	"bytes"
+	"errors"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
 	"testing"
@@ -14,196 +21,400 @@ import (
 	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/analysis/checker"
 	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/types/typeutil"
 )
 
-var rxFixHint = regexp.MustCompile(`\s+//\s+fix:\s+(.+)\s*$`)
+var (
+	rxFixHint   = regexp.MustCompile(`\s+//\s+fix:\s+(.+)\s*$`)
+	rxNoFixHint = regexp.MustCompile(`\s+//\s+want-nofix\s*$`)
+)
 
 func TestTestctxlint_Run(t *testing.T) {
-	// Most of this code is just setting up the analyzer the same way the main
-	// package does behind the scenes, with some irrelevant elements skipped
+	fixtures := []struct {
+		dir      string
+		analyzer *analysis.Analyzer
+	}{
+		{"./fixtures/unfixed/", testctxlint.Analyzer},
+		{"./fixtures/fuzz/", testctxlint.Analyzer},
+		{"./fixtures/aliased/", testctxlint.Analyzer},
+		{"./fixtures/generic/", testctxlint.Analyzer},
+		{"./fixtures/structs/", testctxlint.Analyzer},
+		{"./fixtures/suite/", testctxlint.Analyzer},
+		{"./fixtures/provider/", testctxlint.NewAnalyzer(bddProvider{})},
+		{"./fixtures/ginkgo/", testctxlint.NewAnalyzer(testctxlint.GinkgoProvider{
+			GinkgoPackages: []string{"github.com/icedream/testctxlint/fixtures/ginkgo/stub/ginkgo"},
+			GomegaPackages: []string{"github.com/icedream/testctxlint/fixtures/ginkgo/stub/gomega"},
+		})},
+		{"./fixtures/structural/", analyzerWithFlags(t, map[string][]string{
+			"context-types": {"github.com/icedream/testctxlint/fixtures/structural/testenv," +
+				"github.com/icedream/testctxlint/fixtures/structural/harness.T"},
+		})},
+		{"./fixtures/xnet/", testctxlint.Analyzer},
+		{"./fixtures/cleanup/", testctxlint.Analyzer},
+		{"./fixtures/subtests/", testctxlint.Analyzer},
+		{"./fixtures/factory/", testctxlint.Analyzer},
+		{"./fixtures/pkglevel/", testctxlint.Analyzer},
+		{"./fixtures/facts/", testctxlint.Analyzer},
+		{"./fixtures/ctxparam/", testctxlint.Analyzer},
+		{"./fixtures/suppress/", testctxlint.Analyzer},
+		{"./fixtures/ctxvar/", testctxlint.Analyzer},
+		{"./fixtures/httphandler/", testctxlint.Analyzer},
+		{"./fixtures/imports/", testctxlint.Analyzer},
+		{"./fixtures/cancel/", testctxlint.Analyzer},
+		{"./fixtures/naming/", testctxlint.Analyzer},
+		{"./fixtures/refactor/", analyzerWithFlags(t, map[string][]string{
+			"refactor-helpers": {"true"},
+		})},
+		{"./fixtures/hoist/", analyzerWithFlags(t, map[string][]string{
+			"fix-strategy": {"hoist"},
+		})},
+		{"./fixtures/forbidden/", analyzerWithFlags(t, map[string][]string{
+			"forbidden-funcs": {
+				"github.com/icedream/testctxlint/fixtures/forbidden/ctxutil.NewRoot=ctxutil.Wrap({ctx})",
+				"github.com/icedream/testctxlint/fixtures/forbidden/ctxutil.BackgroundWithTracer=" +
+					"ctxutil.WithTracer({ctx}, {args})",
+				"github.com/icedream/testctxlint/fixtures/forbidden/ctxutil.NewTestRoot",
+			},
+		})},
+	}
 
-	analyzers := []*analysis.Analyzer{testctxlint.Analyzer}
+	// Load all fixtures at once, as dependencies need to be loaded along
+	// with their syntax for their facts
+	dirs := make([]string, len(fixtures))
+	for i, fixture := range fixtures {
+		dirs[i] = fixture.dir
+	}
 
-	assert.NoError(t, analysis.Validate(analyzers))
+	pkgs := loadFixtures(t, dirs...)
+
+	for _, fixture := range fixtures {
+		t.Run(filepath.Base(fixture.dir), func(t *testing.T) {
+			testFixture(t, fixture.analyzer, fixturePackages(t, pkgs, fixture.dir))
+		})
+	}
+}
+
+// TestTestctxlint_OtherModule checks that functions of other modules creating
+// root contexts are not reported, using a fixture module depending on a stub
+// module.
+func TestTestctxlint_OtherModule(t *testing.T) {
+	const dir = "./fixtures/facts/testdata"
+
+	pkgs := loadModuleFixtures(t, dir, "./...")
+	testFixture(t, testctxlint.Analyzer, fixturePackages(t, pkgs, dir))
+}
+
+// loadFixtures loads the fixture packages in dirs, including their tests.
+func loadFixtures(tb testing.TB, dirs ...string) []*packages.Package {
+	tb.Helper()
+
+	return loadModuleFixtures(tb, "", dirs...)
+}
+
+// loadModuleFixtures loads the fixture packages matching patterns within the
+// module in moduleDir, including their tests.
+func loadModuleFixtures(tb testing.TB, moduleDir string, patterns ...string) []*packages.Package {
+	tb.Helper()
 
 	conf := packages.Config{
-		Mode:  packages.LoadSyntax | packages.NeedModule,
+		Mode:  packages.LoadAllSyntax | packages.NeedModule,
+		Dir:   moduleDir,
 		Tests: true,
 	}
 
-	pkgs, err := packages.Load(&conf, "./fixtures/unfixed/")
+	pkgs, err := packages.Load(&conf, patterns...)
+	require.NoError(tb, err)
+	require.NotEmpty(tb, pkgs)
+
+	for _, pkg := range pkgs {
+		require.False(tb, pkg.IllTyped, pkg.ID)
+	}
+
+	return pkgs
+}
+
+// fixturePackages returns the packages of pkgs in dir.
+func fixturePackages(t *testing.T, pkgs []*packages.Package, dir string) []*packages.Package {
+	t.Helper()
+
+	dir, err := filepath.Abs(dir)
 	require.NoError(t, err)
-	require.NotEmpty(t, pkgs)
-	require.False(t, pkgs[0].IllTyped)
+
+	var result []*packages.Package
 
 	for _, pkg := range pkgs {
-		diagnostics := []analysis.Diagnostic{}
-		inputs := make(map[*analysis.Analyzer]any)
-
-		module := &analysis.Module{}
-		if mod := pkg.Module; mod != nil {
-			module.Path = mod.Path
-			module.Version = mod.Version
-			module.GoVersion = mod.GoVersion
+		if len(pkg.GoFiles) > 0 && filepath.Dir(pkg.GoFiles[0]) == dir {
+			result = append(result, pkg)
+		}
+	}
+
+	require.NotEmpty(t, result)
+
+	return result
+}
+
+// analyzerWithFlags returns a new analyzer instance with the given flags set.
+func analyzerWithFlags(t *testing.T, flags map[string][]string) *analysis.Analyzer {
+	t.Helper()
+
+	analyzer := testctxlint.NewAnalyzer()
+	for name, values := range flags {
+		for _, value := range values {
+			require.NoError(t, analyzer.Flags.Set(name, value))
 		}
+	}
+
+	return analyzer
+}
+
+// bddProvider is a [testctxlint.ScopeProvider] for the BDD runner stub in
+// fixtures/provider/bdd.
+type bddProvider struct{}
+
+func (bddProvider) FuncScope(pass *analysis.Pass, fn ast.Node, call *ast.CallExpr) *testctxlint.ContextSource {
+	lit, ok := fn.(*ast.FuncLit)
+	if !ok || call == nil {
+		return nil
+	}
+
+	callee := typeutil.Callee(pass.TypesInfo, call)
+	if callee == nil || callee.Pkg() == nil ||
+		callee.Pkg().Path() != "github.com/icedream/testctxlint/fixtures/provider/bdd" ||
+		callee.Name() != "Scenario" {
+		return nil
+	}
+
+	params := lit.Type.Params.List
+	if len(params) != 1 || len(params[0].Names) != 1 {
+		return nil
+	}
+
+	return &testctxlint.ContextSource{
+		Expr: params[0].Names[0].Name + ".Context()",
+	}
+}
 
-		loadedFiles := map[string][]byte{}
-		readFile := func(n string) ([]byte, error) {
-			n = filepath.Clean(n)
-			if fixedFile, ok := loadedFiles[n]; ok {
-				return fixedFile, nil
+// testFixture runs analyzer on the packages of a fixture and checks the
+// reported diagnostics against the fix hints in the fixture's source code.
+func testFixture(t *testing.T, analyzer *analysis.Analyzer, pkgs []*packages.Package) {
+	t.Helper()
+
+	analyzers := []*analysis.Analyzer{analyzer}
+
+	assert.NoError(t, analysis.Validate(analyzers))
+
+	graph, err := checker.Analyze(analyzers, pkgs, &checker.Options{})
+	require.NoError(t, err)
+
+	for _, action := range graph.Roots {
+		require.NoError(t, action.Err)
+
+		pkg := action.Package
+
+		for _, file := range pkg.Syntax {
+			filename := pkg.Fset.File(file.Pos()).Name()
+
+			var fileDiagnostics []analysis.Diagnostic
+
+			for _, diag := range action.Diagnostics {
+				if pkg.Fset.File(diag.Pos).Name() == filename {
+					fileDiagnostics = append(fileDiagnostics, diag)
+				}
 			}
-			data, err := os.ReadFile(n)
-			if err == nil {
-				loadedFiles[n] = data
+
+			checkFixHints(t, pkg.Fset, filename, fileDiagnostics)
+		}
+	}
+}
+
+// checkFixHints checks the diagnostics reported for a file against the fix
+// hints in it. Each diagnostic must be reported for a line with a fix hint,
+// and applying its first fix must turn that line into the hinted code. Hints
+// may list the code expected from alternative fixes as well, separated by
+// " || ". Lines with a want-nofix hint instead expect a diagnostic whose only
+// fix suppresses it. Each line with a hint must have a diagnostic.
+//
+// If a golden file exists next to the file, applying the fixes of all
+// diagnostics at once must result in the golden file's content.
+// Like with the -fix flag, the result is formatted first.
+func checkFixHints(t *testing.T, fset *token.FileSet, filename string, diagnostics []analysis.Diagnostic) {
+	t.Helper()
+
+	data, err := os.ReadFile(filename)
+	require.NoError(t, err)
+
+	lines := strings.Split(string(data), "\n")
+	caught := map[int]bool{}
+
+	var allEdits []analysis.TextEdit
+
+	for _, diag := range diagnostics {
+		posn := fset.Position(diag.Pos)
+		t.Logf("%s: %s\n", posn, diag.Message)
+
+		// print code snippet with context
+		for i := posn.Line - 1; i <= posn.Line+1; i++ {
+			if i >= 1 && i <= len(lines) {
+				t.Logf("%d\t%s\n", i, lines[i-1])
 			}
-			return data, err
 		}
-		emulateFixedFile := func(n string, data []byte) {
-			n = filepath.Clean(n)
-			loadedFiles[n] = data
+
+		// a want-nofix hint expects a diagnostic without fixes other than
+		// the suppression
+		if rxNoFixHint.MatchString(lines[posn.Line-1]) {
+			caught[posn.Line] = true
+
+			if assert.Len(t, diag.SuggestedFixes, 1, "diagnostic must only have the suppression fix") {
+				assert.Contains(t, diag.SuggestedFixes[0].Message, "//testctxlint:ignore")
+
+				// like the -fix flag, apply it along with the other fixes
+				allEdits = append(allEdits, diag.SuggestedFixes[0].TextEdits...)
+			}
+
+			continue
 		}
 
-		pass := &analysis.Pass{
-			Analyzer:     testctxlint.Analyzer,
-			Fset:         pkg.Fset,
-			Files:        pkg.Syntax,
-			OtherFiles:   pkg.OtherFiles,
-			IgnoredFiles: pkg.IgnoredFiles,
-			Pkg:          pkg.Types,
-			TypesInfo:    pkg.TypesInfo,
-			TypesSizes:   pkg.TypesSizes,
-			TypeErrors:   pkg.TypeErrors,
-			Module:       module,
-
-			ResultOf: inputs,
-			ReadFile: readFile,
-			Report: func(d analysis.Diagnostic) {
-				diagnostics = append(diagnostics, d)
-			},
+		// extract expected fix hint
+		fixHintMatch := rxFixHint.FindStringSubmatch(lines[posn.Line-1])
+		if !assert.NotNil(t, fixHintMatch, "linter must not trigger on correct lines") {
+			continue
 		}
 
-		// simplified dependency loop without fact export/import
-		for _, dep := range testctxlint.Analyzer.Requires {
-			inputs[dep], err = dep.Run(pass)
-			require.NoError(t, err)
+		caught[posn.Line] = true
+
+		// a hint may list the lines expected from alternative fixes after
+		// the first one, separated by ||
+		expected := strings.Split(fixHintMatch[1], " || ")
+		if len(expected) > 1 {
+			assert.Len(t, diag.SuggestedFixes, len(expected), "number of fixes")
 		}
 
-		result, err := testctxlint.Analyzer.Run(pass)
-		assert.NoError(t, err)
-		assert.IsType(t, testctxlint.Analyzer.ResultType, result)
+		messages := map[string]bool{}
 
-		for _, diag := range diagnostics {
-			posn := pkg.Fset.Position(diag.Pos)
-			t.Logf("%s: %s\n", posn, diag.Message)
+		for i, fix := range diag.SuggestedFixes {
+			assert.NotEmpty(t, fix.Message)
+			assert.NotEmpty(t, fix.TextEdits)
+			assert.False(t, messages[fix.Message], "fixes must be labelled distinctly")
+			messages[fix.Message] = true
 
-			end := pkg.Fset.Position(diag.End)
-			if !end.IsValid() {
-				end = posn
-			}
+			// apply the fix to the original file, keeping track of where the
+			// diagnostic's line ends up
+			fixed, offset := applyEdits(t, fset, data, fix.TextEdits, posn.Offset)
 
-			// Note: We now support multi-line fixes, so we don't assert single-line requirement
+			// like the -fix flag, only the first fix is applied along with
+			// those of other diagnostics
+			if i == 0 {
+				allEdits = append(allEdits, fix.TextEdits...)
+			}
 
-			data, _ := pass.ReadFile(posn.Filename)
-			lines := strings.Split(string(data), "\n")
-			for i := posn.Line; i <= end.Line; i++ {
-				line := lines[i-1]
+			if i >= len(expected) {
+				continue
+			}
 
-				// print code snippet with context
-				for i := posn.Line - 1; i <= end.Line+1; i++ {
-					if i >= 1 && i < len(lines) {
-						t.Logf("%d\t%s\n", i, lines[i-1])
-					}
-				}
+			// a hint of <removed> expects the fix to remove the line
+			if expected[i] == "<removed>" {
+				assert.NotContains(t, string(fixed), lines[posn.Line-1])
 
-				// extract expected fix hint
-				fixHintMatch := rxFixHint.FindStringSubmatch(line)
-				if assert.NotNil(t, fixHintMatch, "linter must not trigger on correct lines") {
-					expectedFixedLine := fixHintMatch[1]
-
-					// For multi-line fixes, we need to apply them to the entire file content
-					// then extract the relevant line
-					fileContent := strings.Join(lines, "\n")
-
-					// apply all the fixes
-					for i, fix := range diag.SuggestedFixes {
-						assert.Equal(t, 0, i)
-						assert.NotEmpty(t, fix.Message)
-						assert.NotEmpty(t, fix.TextEdits)
-
-						// Sort edits by position (reverse order to avoid position shifts)
-						edits := make([]analysis.TextEdit, len(fix.TextEdits))
-						copy(edits, fix.TextEdits)
-						sort.Slice(edits, func(i, j int) bool {
-							return edits[i].Pos > edits[j].Pos
-						})
-
-						for _, edit := range edits {
-							fixPosn := pkg.Fset.Position(edit.Pos)
-							fixEndn := pkg.Fset.Position(edit.End)
-							if !fixEndn.IsValid() {
-								fixEndn = fixPosn
-							}
-
-							// Calculate byte offsets in the file content
-							startOffset := 0
-							endOffset := 0
-
-							// Count bytes to the start position
-							fileLines := strings.Split(fileContent, "\n")
-							for lineIdx := 0; lineIdx < fixPosn.Line-1; lineIdx++ {
-								startOffset += len(fileLines[lineIdx]) + 1 // +1 for newline
-							}
-							startOffset += fixPosn.Column - 1
-
-							// Count bytes to the end position
-							endOffset = startOffset
-							if fixEndn.Line != fixPosn.Line {
-								for lineIdx := fixPosn.Line - 1; lineIdx < fixEndn.Line-1; lineIdx++ {
-									endOffset += len(fileLines[lineIdx]) + 1 // +1 for newline
-								}
-								endOffset += fixEndn.Column - 1 - (fixPosn.Column - 1)
-							} else {
-								endOffset += fixEndn.Column - fixPosn.Column
-							}
-
-							// Apply the edit
-							if startOffset <= len(fileContent) && endOffset <= len(fileContent) {
-								fileContent = fileContent[:startOffset] + string(edit.NewText) + fileContent[endOffset:]
-							}
-						}
-					}
-
-					// Extract the line that should have changed
-					newLines := strings.Split(fileContent, "\n")
-					if posn.Line <= len(newLines) {
-						line = newLines[posn.Line-1]
-					}
-
-					// match up end result with fix hint
-					lineWithoutHint := line[0 : len(line)-len(fixHintMatch[0])]
-					assert.Equal(t, expectedFixedLine, strings.TrimSpace(lineWithoutHint))
-
-					// Update the lines array with the new file content for leftover hint checking
-					lines = newLines
-					if posn.Line <= len(lines) {
-						lines[posn.Line-1] = lineWithoutHint
-					}
-				}
+				continue
 			}
 
-			// check if any leftover hints exist (errors the linter did not catch)
-			resultCode := strings.Join(lines, "\n")
-			emulateFixedFile(posn.Filename, []byte(resultCode))
+			// match up end result with fix hint
+			line := lineAt(fixed, offset)
+			lineWithoutHint := strings.TrimSuffix(line, fixHintMatch[0])
+			assert.Equal(t, expected[i], strings.TrimSpace(lineWithoutHint))
 		}
+	}
 
-		for name, data := range loadedFiles {
-			lines := strings.Split(string(data), "\n")
-			for lineIndex, line := range lines {
-				assert.NotRegexp(t, rxFixHint, line, "linter did not catch bad line in %s:%d", name, lineIndex+1)
-			}
+	// check if any leftover hints exist (errors the linter did not catch)
+	for lineIndex, line := range lines {
+		if (rxFixHint.MatchString(line) || rxNoFixHint.MatchString(line)) && !caught[lineIndex+1] {
+			assert.Fail(t, "linter did not catch bad line", "%s:%d", filename, lineIndex+1)
 		}
 	}
+
+	golden, err := os.ReadFile(filename + ".golden")
+	if errors.Is(err, fs.ErrNotExist) {
+		return
+	}
+
+	require.NoError(t, err)
+
+	// format the result like the -fix flag does
+	fixed, _ := applyEdits(t, fset, data, allEdits, 0)
+	if formatted, err := format.Source(fixed); assert.NoError(t, err) {
+		fixed = formatted
+	}
+
+	assert.Equal(t, string(golden), string(fixed), "fixed file does not match %s.golden", filename)
+}
+
+// applyEdits applies edits to src and returns the result along with the
+// offset in it which corresponds to offset in src. Identical edits are only
+// applied once, overlapping edits are reported as a test failure.
+func applyEdits(t *testing.T, fset *token.FileSet, src []byte, edits []analysis.TextEdit, offset int) ([]byte, int) {
+	t.Helper()
+
+	type edit struct {
+		start, end int
+		text       string
+	}
+
+	var sorted []edit
+
+	for _, e := range edits {
+		end := e.End
+		if !end.IsValid() {
+			end = e.Pos
+		}
+
+		ed := edit{fset.Position(e.Pos).Offset, fset.Position(end).Offset, string(e.NewText)}
+		if !slices.Contains(sorted, ed) {
+			sorted = append(sorted, ed)
+		}
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].start < sorted[j].start
+	})
+
+	var (
+		result    []byte
+		last      int
+		newOffset = offset
+	)
+
+	for _, e := range sorted {
+		if !assert.GreaterOrEqual(t, e.start, last, "overlapping edits") {
+			continue
+		}
+
+		result = append(result, src[last:e.start]...)
+		result = append(result, e.text...)
+		last = e.end
+
+		switch {
+		case e.end <= offset:
+			newOffset += len(e.text) - (e.end - e.start)
+		case e.start <= offset:
+			newOffset -= offset - e.start
+		}
+	}
+
+	result = append(result, src[last:]...)
+
+	return result, newOffset
+}
+
+// lineAt returns the line of src containing offset.
+func lineAt(src []byte, offset int) string {
+	start := bytes.LastIndexByte(src[:offset], '\n') + 1
+
+	end := bytes.IndexByte(src[offset:], '\n')
+	if end < 0 {
+		return string(src[start:])
+	}
+
+	return string(src[start : offset+end])
 }
 
 func BenchmarkTestctxlint(b *testing.B) {
@@ -211,15 +422,7 @@ func BenchmarkTestctxlint(b *testing.B) {
 
 	assert.NoError(b, analysis.Validate(analyzers))
 
-	conf := packages.Config{
-		Mode:  packages.LoadSyntax | packages.NeedModule,
-		Tests: true,
-	}
-
-	initial, err := packages.Load(&conf, "./fixtures/unfixed/")
-	require.NoError(b, err)
-	require.NotEmpty(b, initial)
-	require.False(b, initial[0].IllTyped)
+	initial := loadFixtures(b, "./fixtures/unfixed/")
 
 	opts := &checker.Options{}
 